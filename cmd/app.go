@@ -3,43 +3,222 @@ package main
 import (
 	"DBManager/internal/repository"
 	"DBManager/internal/service"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/events"
+	"DBManager/internal/shared/jwtkeys"
+	"DBManager/internal/shared/mailer"
+	"DBManager/internal/shared/migrations"
+	"DBManager/internal/shared/oidc"
+	"DBManager/internal/shared/passwords"
 	"DBManager/internal/shared/postgres"
+	"DBManager/internal/shared/ratelimit"
 	"DBManager/internal/shared/redis"
 	"DBManager/transport"
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/joho/godotenv"
 	"log"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
 func main() {
+	// Подкоманда keys создаёт ключи подписи и не требует ни .env, ни подключения к БД.
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		if err := runKeys(os.Args[2:]); err != nil {
+			log.Fatal("FATAL Error generating key: ", err)
+		}
+		return
+	}
+
+	// Подкоманда oidc-stub запускает заглушку провайдера OpenID Connect для локальной проверки входа.
+	if len(os.Args) > 1 && os.Args[1] == "oidc-stub" {
+		if err := runOIDCStub(os.Args[2:]); err != nil {
+			log.Fatal("FATAL Error running OIDC stub: ", err)
+		}
+		return
+	}
+
 	err := godotenv.Load(".env")
 	if err != nil {
 		log.Fatal("FATAL не удалось загрузить .env")
 	}
 	slog.Info(".env успешно подгружен")
 
+	// Подкоманда config печатает итоговую конфигурацию и больше ничего не делает.
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfig(os.Args[2:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+			log.Fatal("FATAL Error in config: ", err)
+		}
+		return
+	}
+
+	// Конфигурация загружается один раз, до подключения к чему-либо. У migrate свои аргументы,
+	// поэтому флаги конфигурации принимает только запуск сервера, migrate настраивается файлом и окружением.
+	migrate := len(os.Args) > 1 && os.Args[1] == "migrate"
+	flagArgs := os.Args[1:]
+	if migrate {
+		flagArgs = nil
+	}
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	if _, err := config.Load(flags, flagArgs); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatal("FATAL Error loading config: ", err)
+	}
+	if flags.NArg() > 0 {
+		log.Fatalf("FATAL неизвестная подкоманда %q", flags.Arg(0))
+	}
+
+	if migrate {
+		err = runMigrations(os.Args[2:])
+	} else {
+		err = serve()
+	}
+	if err != nil {
+		log.Fatal("FATAL ", err)
+	}
+}
+
+// runMigrations - подкоманда migrate: управляет схемой БД и не запускает сервер.
+func runMigrations(args []string) error {
 	db, err := postgres.InitPostgres()
 	if err != nil {
-		log.Fatal("FATAL Error initializing database: ", err)
+		return fmt.Errorf("error initializing database: %w", err)
 	}
+	defer closeResource("Postgres", func() error { return postgres.Close(db) })
+
+	if err := runMigrate(context.Background(), db, args); err != nil {
+		return fmt.Errorf("error running migrations: %w", err)
+	}
+	return nil
+}
+
+// serve - жизненный цикл сервера: подключения, запуск, остановка по сигналу с ожиданием начатых запросов.
+// Подключения закрываются в порядке, обратном открытию, - и при штатной остановке, и при ошибке запуска.
+func serve() error {
+	// Остановка по SIGINT (Ctrl+C) и SIGTERM (docker stop, Kubernetes).
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := postgres.InitPostgres()
+	if err != nil {
+		return fmt.Errorf("error initializing database: %w", err)
+	}
+	defer closeResource("Postgres", func() error { return postgres.Close(db) })
 
 	slog.Info("Context timeout set to five")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	startCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	rDB := redis.InitRedis(ctx)
+	// Не запускаем сервер на схеме, к которой не применены все миграции.
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return fmt.Errorf("error loading migrations: %w", err)
+	}
+	if err := migrator.Check(startCtx); err != nil {
+		return err
+	}
+
+	rDB, err := redis.InitRedis(startCtx)
+	if err != nil {
+		return err
+	}
+	defer closeResource("Redis", rDB.Close)
 
 	// Определение слоя репозитория.
 	repo := repository.NewAuthRepo(db, rDB)
+	managerRepo := repository.NewManagerRepo(db)
+
+	// Почта для писем сброса пароля и т.д. Письма отправляются в фоне, чтобы не задерживать ответ.
+	transportMailer, err := mailer.New(config.MailerConfig())
+	if err != nil {
+		return fmt.Errorf("error initializing mailer: %w", err)
+	}
+	mail := mailer.NewAsyncMailer(transportMailer)
+
+	// Ключи подписи Access токенов.
+	keys, err := jwtkeys.New(config.TokenConfig())
+	if err != nil {
+		return fmt.Errorf("error loading JWT keys: %w", err)
+	}
+
+	// Вход через внешнего провайдера OpenID Connect, nil - выключен.
+	oidcProvider := oidc.New(config.OIDCConfig())
+
+	// Хэширование и политика паролей, список утёкших паролей читается здесь же.
+	hasher, err := passwords.NewHasher(config.PasswordConfig())
+	if err != nil {
+		return fmt.Errorf("error initializing password hasher: %w", err)
+	}
+	passwordPolicy, err := passwords.NewPolicy(config.PasswordConfig(), hasher)
+	if err != nil {
+		return fmt.Errorf("error loading password policy: %w", err)
+	}
 
 	// Определения сервисного слоя бизнес-логики.
-	authService := service.NewAuth(repo)
+	authService := service.NewAuth(repo, repo, repo, mail, events.NewLogEmitter(), keys, oidcProvider, passwordPolicy, hasher)
+	managerService := service.NewManager(managerRepo)
+
+	// Ограничение частоты запросов.
+	limiter, err := ratelimit.New(config.RateLimitConfig(), rDB)
+	if err != nil {
+		return fmt.Errorf("error initializing rate limiter: %w", err)
+	}
 
 	// Определение транспортного слоя.
-	controller := transport.NewController(authService)
+	controller := transport.NewController(authService, managerService, limiter)
+	server := transport.NewServer(controller, config.HTTPConfig())
+
+	// Запуск сервера. ListenAndServe возвращает ошибку сразу, если порт занят, иначе - после Shutdown.
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Сервер успешно запущен", "addr", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("не удалось запустить сервер на %s: %w", server.Addr, err)
+	case <-ctx.Done():
+	}
+
+	// Повторный сигнал во время остановки завершает процесс сразу, не дожидаясь запросов.
+	stop()
+
+	timeout := config.HTTPConfig().ShutdownTimeout
+	slog.Info("Получен сигнал остановки, ждём завершения начатых запросов", "timeout", timeout)
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), timeout)
+	defer cancelShutdown()
 
-	// Запуск сервера.
-	transport.GoRouter(controller)
+	// Shutdown перестаёт принимать соединения и ждёт, пока начатые запросы получат ответ.
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		// Не уложились в таймаут - обрываем оставшиеся соединения, подключения к БД закроются следом.
+		_ = server.Close()
+		return fmt.Errorf("сервер не остановился за %s: %w", timeout, err)
+	}
+
+	// Письма, поставленные в очередь завершёнными запросами, дописываем в оставшееся время.
+	if err := mail.Wait(shutdownCtx); err != nil {
+		slog.Error("Не все письма отправлены до остановки", "error", err)
+	}
+
+	slog.Info("Сервер остановлен")
+	return nil
+}
+
+// closeResource - закрывает подключение при остановке. Ошибка только логируется: остановку она не отменяет.
+func closeResource(name string, closeFn func() error) {
+	if err := closeFn(); err != nil {
+		slog.Error("Не удалось закрыть соединение", "resource", name, "error", err)
+		return
+	}
+	slog.Info("Соединение закрыто", "resource", name)
 }
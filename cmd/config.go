@@ -0,0 +1,32 @@
+package main
+
+import (
+	"DBManager/internal/shared/config"
+	"errors"
+	"flag"
+	"os"
+)
+
+const configUsage = "использование: app config print [--show-secrets] [флаги конфигурации]"
+
+// runConfig - обрабатывает подкоманду config print: печатает итоговую конфигурацию после применения
+// файла, окружения и флагов. Нужна для отладки развёртываний: видно, какие значения реально подхватились.
+// Конфигурация печатается и тогда, когда не проходит проверку, - ошибки проверки возвращаются после печати.
+// Секреты скрываются, если не передан --show-secrets: вывод часто попадает в логи и тикеты.
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	showSecrets := fs.Bool("show-secrets", false, "показать секреты вместо [REDACTED]")
+	cfg, err := config.Read(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	if err := config.Print(os.Stdout, cfg, !*showSecrets); err != nil {
+		return err
+	}
+	return config.Validate(cfg)
+}
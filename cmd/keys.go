@@ -0,0 +1,63 @@
+package main
+
+import (
+	"DBManager/internal/shared/jwtkeys"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const keysUsage = "использование: app keys generate RS256|EdDSA KID DIR [ACTIVE_FROM в RFC 3339]"
+
+// runKeys - обрабатывает подкоманду keys generate: создаёт приватный ключ в DIR/KID.pem
+// и печатает запись для файла ключей JWT_KEYS_FILE.
+func runKeys(args []string) error {
+	if len(args) < 4 || args[0] != "generate" {
+		return errors.New(keysUsage)
+	}
+	alg, kid, dir := args[1], args[2], args[3]
+
+	activeFrom := time.Now().UTC().Truncate(time.Second)
+	if len(args) > 4 {
+		var err error
+		if activeFrom, err = time.Parse(time.RFC3339, args[4]); err != nil {
+			return fmt.Errorf("некорректная дата начала действия ключа %q", args[4])
+		}
+	}
+
+	pemBytes, err := jwtkeys.Generate(alg)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, kid+".pem")
+	// O_EXCL - существующий ключ не перезаписываем.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(pemBytes); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	entry, err := json.MarshalIndent(map[string]any{
+		"kid":         kid,
+		"alg":         alg,
+		"private_key": kid + ".pem",
+		"active_from": activeFrom.Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Ключ сохранён в %s. Добавьте в файл ключей запись:\n%s\n", path, entry)
+	fmt.Println("У предыдущего ключа укажите retire_at не раньше active_from нового ключа плюс время жизни Access токена.")
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"DBManager/internal/shared/oidc"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+const oidcStubUsage = "использование: app oidc-stub ISSUER EMAIL, например app oidc-stub http://localhost:9000 staff@example.com"
+
+// runOIDCStub - обрабатывает подкоманду oidc-stub: запускает заглушку провайдера на адресе из ISSUER.
+// Приложение настраивается на неё через OIDC_ISSUER=ISSUER, client_id и redirect_uri заглушка принимает любые.
+func runOIDCStub(args []string) error {
+	if len(args) < 2 {
+		return errors.New(oidcStubUsage)
+	}
+
+	issuer, err := url.Parse(args[0])
+	if err != nil || issuer.Host == "" {
+		return errors.New(oidcStubUsage)
+	}
+
+	stub, err := oidc.NewStub(args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Заглушка провайдера OIDC запущена", "issuer", stub.Issuer, "email", stub.Email)
+	return http.ListenAndServe(issuer.Host, stub.Handler())
+}
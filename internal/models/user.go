@@ -3,12 +3,14 @@ package models
 import "time"
 
 type User struct {
-	ID        int
-	FirstName string
-	LastName  string
-	IsAdmin   bool
-	Email     string
-	Hash      string
-	UpdatedAt time.Time
-	CreatedAt time.Time
+	ID            int
+	FirstName     string
+	LastName      string
+	IsAdmin       bool
+	Role          string
+	Email         string
+	EmailVerified bool
+	Hash          string
+	UpdatedAt     time.Time
+	CreatedAt     time.Time
 }
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"DBManager/internal/shared/dto"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// apiKeyTouchInterval - время последнего использования ключа обновляется не чаще этого интервала,
+// чтобы частые запросы сканеров не превращались в запись в БД на каждый запрос.
+const apiKeyTouchInterval = time.Minute
+
+// CreateAPIKey - сохраняет новый API ключ.
+func (ar *AuthRepo) CreateAPIKey(ctx context.Context, key *dto.APIKey) error {
+	if err := ar.conn(ctx).Create(key).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return RecordAlreadyExist
+		}
+		return err
+	}
+	return nil
+}
+
+// GetAPIKeyByPrefix - возвращает ключ по префиксу, в том числе отозванный или истёкший.
+func (ar *AuthRepo) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*dto.APIKey, error) {
+	var key dto.APIKey
+
+	if err := ar.conn(ctx).Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RecordNotFound
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// ListAPIKeys - возвращает ключи пользователя, новые первыми.
+func (ar *AuthRepo) ListAPIKeys(ctx context.Context, userID int) ([]dto.APIKey, error) {
+	var keys []dto.APIKey
+
+	if err := ar.conn(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey - отзывает ключ. Если userID не 0 - только ключ этого пользователя.
+// Если действующего ключа нет - возвращает RecordNotFound.
+func (ar *AuthRepo) RevokeAPIKey(ctx context.Context, keyID, userID int) error {
+	query := ar.conn(ctx).Model(&dto.APIKey{}).Where("id = ? AND revoked_at IS NULL", keyID)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	result := query.Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return RecordNotFound
+	}
+
+	return nil
+}
+
+// TouchAPIKey - запоминает время и адрес последнего использования ключа.
+func (ar *AuthRepo) TouchAPIKey(ctx context.Context, keyID int, ipAddress string) error {
+	now := time.Now()
+
+	return ar.conn(ctx).Model(&dto.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyID, now.Add(-apiKeyTouchInterval)).
+		Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ipAddress,
+		}).Error
+}
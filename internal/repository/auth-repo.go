@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
@@ -28,9 +29,9 @@ func NewAuthRepo(db *gorm.DB, rDB *redis.Client) *AuthRepo {
 func (ar *AuthRepo) GetIDByEmail(ctx context.Context, email string) (int, error) {
 	var user dto.User
 
-	if err := ar.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+	if err := ar.conn(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return 0, err
+			return 0, RecordNotFound
 		}
 		return 0, err
 	}
@@ -38,11 +39,55 @@ func (ar *AuthRepo) GetIDByEmail(ctx context.Context, email string) (int, error)
 	return user.ID, nil
 }
 
+// GetUserByID - получает пользователя из базы по id.
+func (ar *AuthRepo) GetUserByID(ctx context.Context, userID int) (*dto.User, error) {
+	var user dto.User
+
+	if err := ar.conn(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RecordNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// SetUserRole - меняет роль пользователя, синхронизируя с ней флаг IsAdmin.
+func (ar *AuthRepo) SetUserRole(ctx context.Context, userID int, role string, isAdmin bool) error {
+	result := ar.conn(ctx).Model(&dto.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"role":       role,
+		"is_admin":   isAdmin,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return RecordNotFound
+	}
+
+	return nil
+}
+
+// SetEmailVerified - отмечает email пользователя подтверждённым.
+func (ar *AuthRepo) SetEmailVerified(ctx context.Context, userID int) error {
+	if err := ar.conn(ctx).Model(&dto.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"email_verified": true,
+		"updated_at":     time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetHashByID - получает хэш пользователя из базы по id.
 func (ar *AuthRepo) GetHashByID(ctx context.Context, userID int) (string, error) {
 	var user dto.User
 
-	if err := ar.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
+	if err := ar.conn(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
 		return "", err
 	}
 
@@ -51,7 +96,7 @@ func (ar *AuthRepo) GetHashByID(ctx context.Context, userID int) (string, error)
 
 // ChangeHashDB - Заменяет старый хэш пользователя по id на новый.
 func (ar *AuthRepo) ChangeHashDB(ctx context.Context, userID int, hash string) error {
-	if err := ar.db.WithContext(ctx).Model(&dto.User{}).Where("id = ?", userID).Update("hash", hash).Error; err != nil {
+	if err := ar.conn(ctx).Model(&dto.User{}).Where("id = ?", userID).Update("hash", hash).Error; err != nil {
 		return err
 	}
 
@@ -60,14 +105,14 @@ func (ar *AuthRepo) ChangeHashDB(ctx context.Context, userID int, hash string) e
 
 // AddUser - Создаёт запись с новым пользователем в БД.
 func (ar *AuthRepo) AddUser(ctx context.Context, user *dto.User) error {
-	if err := ar.db.WithContext(ctx).Create(user).Error; err != nil {
+	if err := ar.conn(ctx).Create(user).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
 func (ar *AuthRepo) CreateRefreshToken(ctx context.Context, token *dto.RefreshToken) error {
-	if err := ar.db.WithContext(ctx).Create(token).Error; err != nil {
+	if err := ar.conn(ctx).Create(token).Error; err != nil {
 		return err
 	}
 	return nil
@@ -76,7 +121,26 @@ func (ar *AuthRepo) CreateRefreshToken(ctx context.Context, token *dto.RefreshTo
 func (ar *AuthRepo) GetRefreshTokenByHash(ctx context.Context, hash string) (*dto.RefreshToken, error) {
 	var refreshToken dto.RefreshToken
 
-	if err := ar.db.WithContext(ctx).Where("token_hash = ?", hash).First(&refreshToken).Error; err != nil {
+	if err := ar.conn(ctx).Where("token_hash = ?", hash).First(&refreshToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RecordNotFound
+		}
+		return nil, err
+	}
+
+	return &refreshToken, nil
+}
+
+// LockRefreshTokenByHash - получает refresh-токен по хэшу и блокирует его строку до конца транзакции.
+// Имеет смысл только внутри WithTx: параллельный запрос с тем же токеном будет ждать её завершения.
+func (ar *AuthRepo) LockRefreshTokenByHash(ctx context.Context, hash string) (*dto.RefreshToken, error) {
+	var refreshToken dto.RefreshToken
+
+	if err := ar.conn(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", hash).First(&refreshToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RecordNotFound
+		}
 		return nil, err
 	}
 
@@ -85,7 +149,7 @@ func (ar *AuthRepo) GetRefreshTokenByHash(ctx context.Context, hash string) (*dt
 
 // RevokeActiveRefreshTokens отзывает все активные refresh-токены пользователя
 func (ar *AuthRepo) RevokeActiveRefreshTokens(ctx context.Context, userID int) (int, error) {
-	result := ar.db.WithContext(ctx).Model(&dto.RefreshToken{}).
+	result := ar.conn(ctx).Model(&dto.RefreshToken{}).
 		Where("user_id = ? AND is_revoked = ? AND expires_at > ?", userID, false, time.Now()).
 		Update("is_revoked", true)
 
@@ -100,21 +164,101 @@ func (ar *AuthRepo) RevokeActiveRefreshTokens(ctx context.Context, userID int) (
 	return int(result.RowsAffected), nil
 }
 
+// ListActiveRefreshTokens - возвращает действующие refresh-токены пользователя, новые первыми.
+func (ar *AuthRepo) ListActiveRefreshTokens(ctx context.Context, userID int) ([]dto.RefreshToken, error) {
+	var tokens []dto.RefreshToken
+
+	if err := ar.conn(ctx).
+		Where("user_id = ? AND is_revoked = ? AND expires_at > ?", userID, false, time.Now()).
+		Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// RevokeRefreshTokenFamily - отзывает все токены семейства (сессии) пользователя.
+// Если у пользователя нет действующих токенов в семействе - возвращает RecordNotFound.
+func (ar *AuthRepo) RevokeRefreshTokenFamily(ctx context.Context, userID int, familyID string) (int, error) {
+	result := ar.conn(ctx).Model(&dto.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND is_revoked = ?", userID, familyID, false).
+		Update("is_revoked", true)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return 0, RecordNotFound
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+// ListRefreshTokenFamily - возвращает все токены семейства пользователя, включая отозванные.
+func (ar *AuthRepo) ListRefreshTokenFamily(ctx context.Context, userID int, familyID string) ([]dto.RefreshToken, error) {
+	var tokens []dto.RefreshToken
+
+	if err := ar.conn(ctx).Where("user_id = ? AND family_id = ?", userID, familyID).Order("id").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// MarkRefreshTokenRotated - отзывает токен, отмечая, что он был обменян на новый.
+// Повторное предъявление такого токена считается признаком его кражи.
+// Если токен уже отозван - возвращает RecordNotFound, так что обменять его можно только один раз.
+func (ar *AuthRepo) MarkRefreshTokenRotated(ctx context.Context, hash string) error {
+	result := ar.conn(ctx).Model(&dto.RefreshToken{}).
+		Where("token_hash = ? AND is_revoked = ?", hash, false).
+		Updates(map[string]interface{}{
+			"is_revoked": true,
+			"rotated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return RecordNotFound
+	}
+
+	return nil
+}
+
 func (ar *AuthRepo) RevokeRefreshToken(ctx context.Context, hash string) error {
-	if err := ar.db.WithContext(ctx).Model(&dto.RefreshToken{}).Where("token_hash = ?", hash).Update("is_revoked", true).Error; err != nil {
+	if err := ar.conn(ctx).Model(&dto.RefreshToken{}).Where("token_hash = ?", hash).Update("is_revoked", true).Error; err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// AddAccessToBlackList - помещает jti Access Token в чёрный список на оставшееся время его жизни.
 func (ar *AuthRepo) AddAccessToBlackList(ctx context.Context, jti string, expiresAt time.Duration) error {
-	blackListKey := fmt.Sprintf("AccessBlock:%s", jti)
+	// Токен уже истёк - блокировать нечего, Redis к тому же не принимает неположительный TTL.
+	if expiresAt <= 0 {
+		return nil
+	}
 
-	err := ar.rDB.Set(ctx, blackListKey, nil, time.Minute*expiresAt).Err()
+	err := ar.rDB.Set(ctx, accessBlockKey(jti), 1, expiresAt).Err()
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// IsAccessBlocked - проверяет, находится ли jti Access Token в чёрном списке.
+func (ar *AuthRepo) IsAccessBlocked(ctx context.Context, jti string) (bool, error) {
+	count, err := ar.rDB.Exists(ctx, accessBlockKey(jti)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func accessBlockKey(jti string) string {
+	return fmt.Sprintf("AccessBlock:%s", jti)
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"DBManager/internal/shared/dto"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// Попытки входа хранятся в Redis в sorted set: элемент - попытка, score - её время в миллисекундах.
+// Перед подсчётом из множества удаляются попытки старше окна, так окно получается скользящим.
+//
+// Попытка резервируется до проверки пароля и сразу считается неудачной: иначе параллельные запросы
+// прошли бы проверку счётчиков одновременно и получили по попытке каждый, мимо лимитов и задержки.
+// Если вход удался, попытка снимается.
+
+// reserveLoginAttemptScript - атомарно проверяет блокировку, лимиты и задержку и резервирует попытку.
+// KEYS: попытки аккаунта, попытки IP, блокировка аккаунта.
+// ARGV: окно, MaxFailures, MaxIPFailures, длительность блокировки (всё в мс), ID попытки, задержки для 0..MaxFailures-1 попыток.
+// Возвращает {код, повторить через мс, попыток аккаунта}: 0 - зарезервирована, 1 - блокировка, 2 - лимит IP, 3 - задержка.
+// Время берётся из Redis, как и в ограничителе частоты запросов.
+var reserveLoginAttemptScript = redis.NewScript(`
+local window = tonumber(ARGV[1])
+local maxFailures = tonumber(ARGV[2])
+local maxIPFailures = tonumber(ARGV[3])
+local lockoutFor = tonumber(ARGV[4])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local locked = redis.call('PTTL', KEYS[3])
+if locked > 0 then
+	return {1, locked, 0}
+end
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now - window)
+
+if redis.call('ZCARD', KEYS[2]) >= maxIPFailures then
+	return {2, window, 0}
+end
+
+local byEmail = redis.call('ZCARD', KEYS[1])
+if byEmail >= maxFailures then
+	return {1, lockoutFor, byEmail}
+end
+
+local delay = tonumber(ARGV[6 + byEmail])
+if delay > 0 then
+	local last = redis.call('ZREVRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	if #last > 0 then
+		local wait = tonumber(last[2]) + delay - now
+		if wait > 0 then
+			return {3, wait, byEmail}
+		end
+	end
+end
+
+redis.call('ZADD', KEYS[1], now, ARGV[5])
+redis.call('ZADD', KEYS[2], now, ARGV[5])
+redis.call('PEXPIRE', KEYS[1], window)
+redis.call('PEXPIRE', KEYS[2], window)
+return {0, 0, byEmail + 1}
+`)
+
+// ReserveLoginAttempt - резервирует попытку входа в аккаунт email с адреса ip, если её позволяют блокировка,
+// лимиты и прогрессивная задержка. Иначе возвращает попытку с причиной отказа.
+func (ar *AuthRepo) ReserveLoginAttempt(ctx context.Context, email, ip string, limits *dto.LoginAttemptLimits) (*dto.LoginAttempt, error) {
+	id := uuid.NewString()
+
+	args := []any{
+		limits.Window.Milliseconds(), limits.MaxFailures, limits.MaxIPFailures, limits.LockoutFor.Milliseconds(), id,
+	}
+	for _, delay := range limits.Delays {
+		args = append(args, delay.Milliseconds())
+	}
+
+	keys := []string{loginFailEmailKey(email), loginFailIPKey(ip), accountLockKey(email)}
+	values, err := reserveLoginAttemptScript.Run(ctx, ar.rDB, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := &dto.LoginAttempt{
+		ByEmail:    int(values[2]),
+		RetryAfter: time.Duration(values[1]) * time.Millisecond,
+	}
+	switch values[0] {
+	case 0:
+		attempt.ID = id
+	case 1:
+		attempt.Denied = dto.LoginDeniedLocked
+	case 2:
+		attempt.Denied = dto.LoginDeniedIP
+	default:
+		attempt.Denied = dto.LoginDeniedDelay
+	}
+
+	return attempt, nil
+}
+
+// ReleaseLoginAttempt - снимает зарезервированную попытку: она не закончилась ни успехом, ни неудачей (например, ошибка БД).
+func (ar *AuthRepo) ReleaseLoginAttempt(ctx context.Context, email, ip, attemptID string) error {
+	pipe := ar.rDB.TxPipeline()
+	pipe.ZRem(ctx, loginFailEmailKey(email), attemptID)
+	pipe.ZRem(ctx, loginFailIPKey(ip), attemptID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ResetLoginFailures - после успешного входа сбрасывает счётчик попыток аккаунта и снимает удавшуюся попытку со счётчика IP.
+// Прежние неудачные попытки с IP остаются: они могли быть к другим аккаунтам.
+func (ar *AuthRepo) ResetLoginFailures(ctx context.Context, email, ip, attemptID string) error {
+	pipe := ar.rDB.TxPipeline()
+	pipe.Del(ctx, loginFailEmailKey(email))
+	pipe.ZRem(ctx, loginFailIPKey(ip), attemptID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// LockAccount - временно блокирует вход в аккаунт на время ttl.
+func (ar *AuthRepo) LockAccount(ctx context.Context, email string, ttl time.Duration) error {
+	return ar.rDB.Set(ctx, accountLockKey(email), 1, ttl).Err()
+}
+
+// UnlockAccount - снимает блокировку аккаунта и сбрасывает его счётчик неудачных попыток.
+func (ar *AuthRepo) UnlockAccount(ctx context.Context, email string) error {
+	return ar.rDB.Del(ctx, accountLockKey(email), loginFailEmailKey(email)).Err()
+}
+
+func loginFailEmailKey(email string) string {
+	return fmt.Sprintf("LoginFail:email:%s", email)
+}
+
+func loginFailIPKey(ip string) string {
+	return fmt.Sprintf("LoginFail:ip:%s", ip)
+}
+
+func accountLockKey(email string) string {
+	return fmt.Sprintf("LoginLock:%s", email)
+}
@@ -1,8 +1,17 @@
 package repository
 
 import (
+	"DBManager/internal/shared/dto"
 	"context"
+	"errors"
 	"gorm.io/gorm"
+	"strings"
+)
+
+// Errors:
+var (
+	RecordAlreadyExist = errors.New("запись с такими данными уже существует в Базе Данных")
+	RecordInUse        = errors.New("на запись ссылаются другие записи в Базе Данных")
 )
 
 type ManagerRepo struct {
@@ -13,10 +22,103 @@ func NewManagerRepo(db *gorm.DB) *ManagerRepo {
 	return &ManagerRepo{db: db}
 }
 
-func (mr *ManagerRepo) DeleteRecord(ctx context.Context, recordID int) error {
+// CreateItem - создаёт запись с новым товаром в БД.
+func (mr *ManagerRepo) CreateItem(ctx context.Context, item *dto.Item) error {
+	if err := mr.conn(ctx).Create(item).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return RecordAlreadyExist
+		}
+		return err
+	}
 	return nil
 }
 
-func (mr *ManagerRepo) CreateRecord(ctx context.Context, recordID int) error {
+// GetItemByID - получает товар из БД по id.
+func (mr *ManagerRepo) GetItemByID(ctx context.Context, itemID int) (*dto.Item, error) {
+	var item dto.Item
+
+	if err := mr.conn(ctx).Where("id = ?", itemID).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RecordNotFound
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// GetItemBySKU - получает товар из БД по артикулу.
+func (mr *ManagerRepo) GetItemBySKU(ctx context.Context, sku string) (*dto.Item, error) {
+	var item dto.Item
+
+	if err := mr.conn(ctx).Where("sku = ?", sku).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RecordNotFound
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// ListItems - возвращает товары, подходящие под фильтр, отсортированные по id.
+func (mr *ManagerRepo) ListItems(ctx context.Context, filter *dto.ItemFilter) ([]dto.Item, error) {
+	query := mr.conn(ctx).Model(&dto.Item{})
+
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Search != "" {
+		pattern := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where(`sku ILIKE ? ESCAPE '\' OR name ILIKE ? ESCAPE '\'`, pattern, pattern)
+	}
+
+	var items []dto.Item
+	if err := query.Order("id").Limit(filter.Limit).Offset(filter.Offset).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// UpdateItem - сохраняет изменения товара в БД.
+func (mr *ManagerRepo) UpdateItem(ctx context.Context, item *dto.Item) error {
+	result := mr.conn(ctx).Model(item).Select("*").Omit("created_at").Updates(item)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return RecordAlreadyExist
+		}
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return RecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteItem - удаляет товар из БД по id. Товар, по которому есть остатки или движения, удалить нельзя - RecordInUse.
+func (mr *ManagerRepo) DeleteItem(ctx context.Context, itemID int) error {
+	result := mr.conn(ctx).Where("id = ?", itemID).Delete(&dto.Item{})
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return RecordInUse
+		}
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return RecordNotFound
+	}
+
 	return nil
 }
+
+// likeEscaper - экранирует спецсимволы шаблона LIKE обратной косой чертой.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike - строка поиска, которая в LIKE ... ESCAPE '\' совпадает только сама с собой.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		name   string
+		search string
+		want   string
+	}{
+		{name: "без спецсимволов", search: "Болт М8", want: "Болт М8"},
+		{name: "процент", search: "100%", want: `100\%`},
+		{name: "подчёркивание", search: "SKU_01", want: `SKU\_01`},
+		{name: "обратная косая черта", search: `a\b`, want: `a\\b`},
+		{name: "всё вместе", search: `\%_`, want: `\\\%\_`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeLike(tt.search); got != tt.want {
+				t.Fatalf("escapeLike(%q) = %q, ожидалось %q", tt.search, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"DBManager/internal/shared/dto"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// GetTOTP - возвращает TOTP секрет пользователя. Если пользователь не начинал подключение - RecordNotFound.
+func (ar *AuthRepo) GetTOTP(ctx context.Context, userID int) (*dto.UserTOTP, error) {
+	var totp dto.UserTOTP
+
+	if err := ar.conn(ctx).Where("user_id = ?", userID).First(&totp).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RecordNotFound
+		}
+		return nil, err
+	}
+
+	return &totp, nil
+}
+
+// SaveTOTPSecret - сохраняет новый, ещё не подтверждённый секрет, заменяя прежний.
+func (ar *AuthRepo) SaveTOTPSecret(ctx context.Context, userID int, secret string) error {
+	totp := dto.UserTOTP{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+
+	return ar.conn(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"secret":       secret,
+			"confirmed_at": nil,
+			"last_step":    0,
+			"created_at":   totp.CreatedAt,
+		}),
+	}).Create(&totp).Error
+}
+
+// ConfirmTOTP - включает вторую ступень для пользователя.
+func (ar *AuthRepo) ConfirmTOTP(ctx context.Context, userID int) error {
+	result := ar.conn(ctx).Model(&dto.UserTOTP{}).Where("user_id = ?", userID).Update("confirmed_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return RecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteTOTP - отключает вторую ступень: удаляет секрет и коды восстановления.
+func (ar *AuthRepo) DeleteTOTP(ctx context.Context, userID int) error {
+	return ar.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&dto.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&dto.UserTOTP{}).Error
+	})
+}
+
+// UseTOTPStep - запоминает интервал принятого кода. Если код из этого или более позднего
+// интервала уже принимался - возвращает RecordNotFound: перехваченный код не сработает повторно.
+func (ar *AuthRepo) UseTOTPStep(ctx context.Context, userID int, step int64) error {
+	result := ar.conn(ctx).Model(&dto.UserTOTP{}).
+		Where("user_id = ? AND last_step < ?", userID, step).
+		Update("last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return RecordNotFound
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes - заменяет коды восстановления пользователя новыми.
+func (ar *AuthRepo) ReplaceRecoveryCodes(ctx context.Context, userID int, hashes []string) error {
+	return ar.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&dto.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]dto.RecoveryCode, 0, len(hashes))
+		for _, hash := range hashes {
+			codes = append(codes, dto.RecoveryCode{UserID: userID, CodeHash: hash, CreatedAt: time.Now()})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// ConsumeRecoveryCode - атомарно помечает код восстановления использованным.
+// Если код не найден или уже использован - возвращает RecordNotFound.
+func (ar *AuthRepo) ConsumeRecoveryCode(ctx context.Context, userID int, hash string) error {
+	result := ar.conn(ctx).Model(&dto.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return RecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"DBManager/internal/shared/dto"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"time"
+)
+
+// GetUserIdentity - возвращает привязку внешнего аккаунта по издателю и subject. Если привязки нет - RecordNotFound.
+func (ar *AuthRepo) GetUserIdentity(ctx context.Context, issuer, subject string) (*dto.UserIdentity, error) {
+	var identity dto.UserIdentity
+
+	if err := ar.conn(ctx).Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RecordNotFound
+		}
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// CreateUserIdentity - привязывает внешний аккаунт к пользователю. Если аккаунт уже привязан - RecordAlreadyExist.
+func (ar *AuthRepo) CreateUserIdentity(ctx context.Context, identity *dto.UserIdentity) error {
+	if err := ar.conn(ctx).Create(identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return RecordAlreadyExist
+		}
+		return err
+	}
+	return nil
+}
+
+// TouchUserIdentity - запоминает время входа через внешний аккаунт и его актуальный email.
+func (ar *AuthRepo) TouchUserIdentity(ctx context.Context, identityID int, email string) error {
+	return ar.conn(ctx).Model(&dto.UserIdentity{}).Where("id = ?", identityID).Updates(map[string]interface{}{
+		"email":         email,
+		"last_login_at": time.Now(),
+	}).Error
+}
+
+// SaveOIDCState - сохраняет данные начатого входа через провайдера на время ttl. Ключ - хэш state.
+func (ar *AuthRepo) SaveOIDCState(ctx context.Context, stateHash string, state *dto.OIDCState, ttl time.Duration) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return ar.rDB.Set(ctx, oidcStateKey(stateHash), data, ttl).Err()
+}
+
+// ConsumeOIDCState - атомарно забирает данные начатого входа: второй раз с тем же state войти нельзя.
+// Если вход не начинался или истёк - возвращает RecordNotFound.
+func (ar *AuthRepo) ConsumeOIDCState(ctx context.Context, stateHash string) (*dto.OIDCState, error) {
+	data, err := ar.rDB.GetDel(ctx, oidcStateKey(stateHash)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, RecordNotFound
+		}
+		return nil, err
+	}
+
+	var state dto.OIDCState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+func oidcStateKey(stateHash string) string {
+	return fmt.Sprintf("OIDCState:%s", stateHash)
+}
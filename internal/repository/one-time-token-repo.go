@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"DBManager/internal/shared/dto"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// CreateOneTimeToken - создаёт запись с одноразовым токеном в БД.
+func (ar *AuthRepo) CreateOneTimeToken(ctx context.Context, token *dto.OneTimeToken) error {
+	if err := ar.conn(ctx).Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ConsumeOneTimeToken - атомарно помечает действующий токен использованным и возвращает id его владельца.
+// Если токен не найден, истёк или уже использован - возвращает RecordNotFound.
+func (ar *AuthRepo) ConsumeOneTimeToken(ctx context.Context, purpose, hash string) (int, error) {
+	var token dto.OneTimeToken
+
+	result := ar.conn(ctx).Model(&token).Clauses(clause.Returning{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, time.Now()).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return 0, RecordNotFound
+	}
+
+	return token.UserID, nil
+}
+
+// GetOneTimeToken - возвращает действующий токен, не помечая его использованным.
+// Если токен не найден, истёк или уже использован - возвращает RecordNotFound.
+func (ar *AuthRepo) GetOneTimeToken(ctx context.Context, purpose, hash string) (*dto.OneTimeToken, error) {
+	var token dto.OneTimeToken
+
+	if err := ar.conn(ctx).Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, time.Now()).
+		First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RecordNotFound
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// LastOneTimeTokenAt - возвращает время выпуска последнего токена пользователя с указанным назначением.
+func (ar *AuthRepo) LastOneTimeTokenAt(ctx context.Context, userID int, purpose string) (time.Time, error) {
+	var token dto.OneTimeToken
+
+	if err := ar.conn(ctx).Where("user_id = ? AND purpose = ?", userID, purpose).
+		Order("created_at DESC").First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return time.Time{}, RecordNotFound
+		}
+		return time.Time{}, err
+	}
+
+	return token.CreatedAt, nil
+}
+
+// InvalidateOneTimeTokens - гасит все неиспользованные токены пользователя с указанным назначением.
+func (ar *AuthRepo) InvalidateOneTimeTokens(ctx context.Context, userID int, purpose string) error {
+	if err := ar.conn(ctx).Model(&dto.OneTimeToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
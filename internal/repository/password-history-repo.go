@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"DBManager/internal/shared/dto"
+	"context"
+	"time"
+)
+
+// GetPasswordHistory - возвращает хэши limit последних прежних паролей пользователя, от новых к старым.
+func (ar *AuthRepo) GetPasswordHistory(ctx context.Context, userID, limit int) ([]string, error) {
+	var hashes []string
+
+	if err := ar.conn(ctx).Model(&dto.PasswordHistory{}).Where("user_id = ?", userID).
+		Order("id DESC").Limit(limit).Pluck("hash", &hashes).Error; err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// PushPasswordHistory - сохраняет хэш прежнего пароля пользователя и удаляет записи сверх keep последних.
+func (ar *AuthRepo) PushPasswordHistory(ctx context.Context, userID int, hash string, keep int) error {
+	if err := ar.conn(ctx).Create(&dto.PasswordHistory{
+		UserID:    userID,
+		Hash:      hash,
+		CreatedAt: time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+
+	recent := ar.conn(ctx).Model(&dto.PasswordHistory{}).Select("id").Where("user_id = ?", userID).Order("id DESC").Limit(keep)
+	return ar.conn(ctx).Where("user_id = ? AND id NOT IN (?)", userID, recent).Delete(&dto.PasswordHistory{}).Error
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"DBManager/internal/shared/dto"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// Errors:
+var (
+	NotEnoughStock = errors.New("недостаточно товара в месте хранения")
+)
+
+// ledgerBalanceQuery - остатки, вычисленные из журнала движений: приходы со знаком плюс, расходы со знаком минус.
+// Исправления по сверке не учитываются: они приводят к журналу таблицу остатков, а не сам журнал.
+const ledgerBalanceQuery = `
+SELECT item_id, location_id, SUM(quantity) AS quantity FROM (
+	SELECT item_id, to_location_id AS location_id, quantity FROM stock_movements
+	WHERE to_location_id IS NOT NULL AND type <> 'reconciliation'
+	UNION ALL
+	SELECT item_id, from_location_id AS location_id, -quantity FROM stock_movements
+	WHERE from_location_id IS NOT NULL AND type <> 'reconciliation'
+) AS ledger`
+
+// CreateMovement - добавляет запись в журнал движений и в той же транзакции обновляет таблицу остатков.
+// Если в месте-источнике недостаточно товара - возвращает NotEnoughStock и ничего не сохраняет.
+// Строки остатков блокируются в порядке места хранения, как в LockStockLevels, чтобы встречные перемещения
+// не взаимоблокировались.
+func (mr *ManagerRepo) CreateMovement(ctx context.Context, movement *dto.StockMovement) error {
+	var steps []func(tx *gorm.DB) error
+	if movement.FromLocationID != nil {
+		steps = append(steps, func(tx *gorm.DB) error {
+			return takeStock(tx, movement.ItemID, *movement.FromLocationID, movement.Quantity)
+		})
+	}
+	if movement.ToLocationID != nil {
+		put := func(tx *gorm.DB) error {
+			return putStock(tx, movement.ItemID, *movement.ToLocationID, movement.Quantity)
+		}
+		if movement.FromLocationID != nil && *movement.ToLocationID < *movement.FromLocationID {
+			steps = append([]func(tx *gorm.DB) error{put}, steps...)
+		} else {
+			steps = append(steps, put)
+		}
+	}
+
+	return mr.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, step := range steps {
+			if err := step(tx); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(movement).Error
+	})
+}
+
+// ListMovements - возвращает записи журнала движений, новые первыми.
+func (mr *ManagerRepo) ListMovements(ctx context.Context, filter *dto.MovementFilter) ([]dto.StockMovement, error) {
+	query := mr.conn(ctx).Model(&dto.StockMovement{})
+
+	if filter.ItemID != 0 {
+		query = query.Where("item_id = ?", filter.ItemID)
+	}
+	if filter.LocationID != 0 {
+		query = query.Where("from_location_id = ? OR to_location_id = ?", filter.LocationID, filter.LocationID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	var movements []dto.StockMovement
+	if err := query.Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&movements).Error; err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// GetLedgerBalances - вычисляет остатки по журналу движений. Если itemID равен 0 - по всем товарам.
+func (mr *ManagerRepo) GetLedgerBalances(ctx context.Context, itemID int) ([]dto.StockBalance, error) {
+	query := ledgerBalanceQuery
+	var args []interface{}
+	if itemID != 0 {
+		query += " WHERE item_id = ?"
+		args = append(args, itemID)
+	}
+	query += " GROUP BY item_id, location_id"
+
+	var balances []dto.StockBalance
+	if err := mr.conn(ctx).Raw(query, args...).Scan(&balances).Error; err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// GetStockLevels - возвращает строки таблицы остатков. Если itemID равен 0 - по всем товарам.
+func (mr *ManagerRepo) GetStockLevels(ctx context.Context, itemID int) ([]dto.StockLevel, error) {
+	query := mr.conn(ctx).Model(&dto.StockLevel{})
+	if itemID != 0 {
+		query = query.Where("item_id = ?", itemID)
+	}
+
+	var levels []dto.StockLevel
+	if err := query.Find(&levels).Error; err != nil {
+		return nil, err
+	}
+
+	return levels, nil
+}
+
+// LockStockLevels - возвращает строки таблицы остатков, блокируя их до конца транзакции из контекста.
+// Если itemID равен 0 - по всем товарам. Строки блокируются в порядке ключа, чтобы параллельные сверки не взаимоблокировались.
+func (mr *ManagerRepo) LockStockLevels(ctx context.Context, itemID int) ([]dto.StockLevel, error) {
+	query := mr.conn(ctx).Model(&dto.StockLevel{}).Clauses(clause.Locking{Strength: "UPDATE"})
+	if itemID != 0 {
+		query = query.Where("item_id = ?", itemID)
+	}
+
+	var levels []dto.StockLevel
+	if err := query.Order("item_id, location_id").Find(&levels).Error; err != nil {
+		return nil, err
+	}
+
+	return levels, nil
+}
+
+// SetStockLevel - перезаписывает остаток значением из level и записывает исправление movement в журнал.
+// Строку остатка, которой не было при сверке, создаёт, только если её не создало параллельное движение:
+// тогда остаток уже согласован с журналом, ничего не меняется и возвращается false.
+func (mr *ManagerRepo) SetStockLevel(ctx context.Context, level *dto.StockLevel, movement *dto.StockMovement) (bool, error) {
+	applied := false
+
+	err := mr.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&dto.StockLevel{}).
+			Where("item_id = ? AND location_id = ?", level.ItemID, level.LocationID).
+			Updates(map[string]interface{}{"quantity": level.Quantity, "updated_at": level.UpdatedAt})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			res = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(level)
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+		}
+
+		applied = true
+		return tx.Create(movement).Error
+	})
+
+	return applied, err
+}
+
+// takeStock - блокирует строку остатка и списывает количество, не допуская отрицательного остатка.
+func takeStock(tx *gorm.DB, itemID, locationID int, quantity dto.Quantity) error {
+	var level dto.StockLevel
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("item_id = ? AND location_id = ?", itemID, locationID).
+		First(&level).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return NotEnoughStock
+		}
+		return err
+	}
+
+	if level.Quantity < quantity {
+		return NotEnoughStock
+	}
+
+	return tx.Model(&level).Updates(map[string]interface{}{
+		"quantity":   gorm.Expr("quantity - ?", quantity),
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// putStock - увеличивает остаток, создавая строку, если товара в месте хранения ещё не было.
+func putStock(tx *gorm.DB, itemID, locationID int, quantity dto.Quantity) error {
+	level := dto.StockLevel{
+		ItemID:     itemID,
+		LocationID: locationID,
+		Quantity:   quantity,
+		UpdatedAt:  time.Now(),
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "item_id"}, {Name: "location_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"quantity":   gorm.Expr("stock_levels.quantity + EXCLUDED.quantity"),
+			"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Create(&level).Error
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+// txKey - ключ контекста, под которым хранится открытая транзакция.
+type txKey struct{}
+
+// withTx - выполняет fn в транзакции, передавая её через контекст. Методы любого репозитория,
+// вызванные с этим контекстом, работают внутри неё. Вложенный вызов создаёт savepoint во внешней транзакции.
+func withTx(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	return conn(ctx, db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn - возвращает транзакцию из контекста, если она открыта, иначе обычное подключение.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// WithTx - выполняет fn в одной транзакции БД. Ошибка из fn откатывает все изменения.
+func (ar *AuthRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, ar.db, fn)
+}
+
+// WithTx - выполняет fn в одной транзакции БД. Ошибка из fn откатывает все изменения.
+func (mr *ManagerRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, mr.db, fn)
+}
+
+func (ar *AuthRepo) conn(ctx context.Context) *gorm.DB {
+	return conn(ctx, ar.db)
+}
+
+func (mr *ManagerRepo) conn(ctx context.Context) *gorm.DB {
+	return conn(ctx, mr.db)
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"DBManager/internal/shared/dto"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+)
+
+// CreateWarehouse - создаёт запись с новым складом в БД.
+func (mr *ManagerRepo) CreateWarehouse(ctx context.Context, warehouse *dto.Warehouse) error {
+	if err := mr.conn(ctx).Create(warehouse).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return RecordAlreadyExist
+		}
+		return err
+	}
+	return nil
+}
+
+// GetWarehouseByID - получает склад из БД по id.
+func (mr *ManagerRepo) GetWarehouseByID(ctx context.Context, warehouseID int) (*dto.Warehouse, error) {
+	var warehouse dto.Warehouse
+
+	if err := mr.conn(ctx).Where("id = ?", warehouseID).First(&warehouse).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RecordNotFound
+		}
+		return nil, err
+	}
+
+	return &warehouse, nil
+}
+
+// ListWarehouses - возвращает все склады, отсортированные по id.
+func (mr *ManagerRepo) ListWarehouses(ctx context.Context) ([]dto.Warehouse, error) {
+	var warehouses []dto.Warehouse
+
+	if err := mr.conn(ctx).Order("id").Find(&warehouses).Error; err != nil {
+		return nil, err
+	}
+
+	return warehouses, nil
+}
+
+// CreateLocation - создаёт запись с новым местом хранения в БД.
+func (mr *ManagerRepo) CreateLocation(ctx context.Context, location *dto.Location) error {
+	if err := mr.conn(ctx).Create(location).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return RecordAlreadyExist
+		}
+		return err
+	}
+	return nil
+}
+
+// GetLocationByID - получает место хранения из БД по id.
+func (mr *ManagerRepo) GetLocationByID(ctx context.Context, locationID int) (*dto.Location, error) {
+	var location dto.Location
+
+	if err := mr.conn(ctx).Where("id = ?", locationID).First(&location).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RecordNotFound
+		}
+		return nil, err
+	}
+
+	return &location, nil
+}
+
+// ListLocations - возвращает места хранения склада, отсортированные по коду.
+func (mr *ManagerRepo) ListLocations(ctx context.Context, warehouseID int) ([]dto.Location, error) {
+	var locations []dto.Location
+
+	if err := mr.conn(ctx).Where("warehouse_id = ?", warehouseID).Order("code").Find(&locations).Error; err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+// GetItemStock - возвращает остатки товара по местам хранения.
+// Если warehouseID равен 0 - по всем складам, иначе только по указанному.
+func (mr *ManagerRepo) GetItemStock(ctx context.Context, itemID, warehouseID int) ([]dto.LocationStock, error) {
+	query := mr.conn(ctx).Table("stock_levels").
+		Select("stock_levels.location_id, locations.code AS location_code, locations.warehouse_id, stock_levels.quantity").
+		Joins("JOIN locations ON locations.id = stock_levels.location_id").
+		Where("stock_levels.item_id = ? AND stock_levels.quantity <> 0", itemID)
+
+	if warehouseID != 0 {
+		query = query.Where("locations.warehouse_id = ?", warehouseID)
+	}
+
+	var stock []dto.LocationStock
+	if err := query.Order("locations.warehouse_id, locations.code").Scan(&stock).Error; err != nil {
+		return nil, err
+	}
+
+	return stock, nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"DBManager/internal/repository"
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/rbac"
+	"DBManager/internal/shared/utils"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// apiKeyPrefix - метка в начале ключа, по ней ключ легко найти в логах и репозиториях.
+const apiKeyPrefix = "mik"
+
+var apiKeyEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// CreateAPIKey - выпускает API ключ для текущего пользователя или, если это администратор, для указанного.
+// Ключ не может дать больше прав, чем есть у роли владельца.
+func (a *Auth) CreateAPIKey(ctx context.Context, claims *dto.AccessToken, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Scopes) == 0 {
+		return nil, errors2.ErrInvalidAPIKeyFormat
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors2.ErrInvalidAPIKeyFormat
+	}
+
+	ownerID := claims.UserID
+	if req.UserID != 0 && req.UserID != claims.UserID {
+		if !rbac.Can(rbac.Role(claims.Role), rbac.PermUsersManage) {
+			return nil, errors2.ErrForbidden
+		}
+		ownerID = req.UserID
+	}
+
+	owner, err := a.repo.GetUserByID(ctx, ownerID)
+	if err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return nil, errors2.UserNotExist
+		}
+		return nil, err
+	}
+
+	role := a.effectiveRole(owner)
+	for _, scope := range req.Scopes {
+		permission := rbac.Permission(scope)
+		if !rbac.IsValidPermission(permission) {
+			return nil, errors2.ErrInvalidAPIKeyFormat
+		}
+		if !rbac.Can(role, permission) {
+			return nil, errors2.ErrAPIKeyScopeNotAllowed
+		}
+		// Ключ с правом на изменение остатков - такой же сильный доступ, как сессия со второй ступенью.
+		if config.AuthConfig().MFAEnforced && rbac.RequiresMFA(permission) && !claims.MFA {
+			return nil, errors2.ErrMFARequired
+		}
+	}
+
+	prefix, err := randomAPIKeyPart(5)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomAPIKeyPart(20)
+	if err != nil {
+		return nil, err
+	}
+
+	key := dto.APIKey{
+		UserID:     owner.ID,
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: utils.HashToken(secret),
+		Scopes:     req.Scopes,
+		ExpiresAt:  req.ExpiresAt,
+		CreatedBy:  &claims.UserID,
+		CreatedAt:  time.Now(),
+	}
+	if err := a.repo.CreateAPIKey(ctx, &key); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Выпущен API ключ", "key_id", key.ID, "user_id", owner.ID, "created_by", claims.UserID)
+	return &dto.CreateAPIKeyResponse{
+		Key:    apiKeyPrefix + "_" + prefix + "_" + secret,
+		APIKey: key,
+	}, nil
+}
+
+// ListAPIKeys - возвращает ключи пользователя userID. Чужие ключи может просматривать только администратор.
+func (a *Auth) ListAPIKeys(ctx context.Context, claims *dto.AccessToken, userID int) ([]dto.APIKey, error) {
+	if userID == 0 {
+		userID = claims.UserID
+	}
+	if userID != claims.UserID && !rbac.Can(rbac.Role(claims.Role), rbac.PermUsersManage) {
+		return nil, errors2.ErrForbidden
+	}
+
+	return a.repo.ListAPIKeys(ctx, userID)
+}
+
+// RevokeAPIKey - отзывает ключ. Пользователь может отозвать свой ключ, администратор - любой.
+func (a *Auth) RevokeAPIKey(ctx context.Context, claims *dto.AccessToken, keyID int) error {
+	ownerID := claims.UserID
+	if rbac.Can(rbac.Role(claims.Role), rbac.PermUsersManage) {
+		ownerID = 0
+	}
+
+	if err := a.repo.RevokeAPIKey(ctx, keyID, ownerID); err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return errors2.ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	slog.Info("API ключ отозван", "key_id", keyID, "revoked_by", claims.UserID)
+	return nil
+}
+
+// ValidateAPIKey - проверяет API ключ и возвращает данные для авторизации запроса.
+// Роль берётся актуальная, права дополнительно ограничиваются scopes ключа.
+func (a *Auth) ValidateAPIKey(ctx context.Context, rawKey, ipAddress string) (*dto.AccessToken, error) {
+	label, rest, ok := strings.Cut(rawKey, "_")
+	if !ok || label != apiKeyPrefix {
+		return nil, errors2.ErrAPIKeyInvalid
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok {
+		return nil, errors2.ErrAPIKeyInvalid
+	}
+
+	key, err := a.repo.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return nil, errors2.ErrAPIKeyInvalid
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.SecretHash), []byte(utils.HashToken(secret))) != 1 {
+		return nil, errors2.ErrAPIKeyInvalid
+	}
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt)) {
+		return nil, errors2.ErrAPIKeyInvalid
+	}
+
+	user, err := a.repo.GetUserByID(ctx, key.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ошибка учёта использования не должна ронять запрос интеграции.
+	if err := a.repo.TouchAPIKey(ctx, key.ID, ipAddress); err != nil {
+		slog.Error("Не удалось обновить время использования API ключа", "key_id", key.ID, "error", err)
+	}
+
+	return &dto.AccessToken{
+		UserID:   user.ID,
+		Role:     string(a.effectiveRole(user)),
+		MFA:      true,
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// randomAPIKeyPart - случайная строка из n байт в base32 нижнего регистра.
+func randomAPIKeyPart(n int) (string, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return strings.ToLower(apiKeyEncoding.EncodeToString(raw)), nil
+}
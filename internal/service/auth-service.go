@@ -5,111 +5,198 @@ import (
 	errors2 "DBManager/internal/service/errors"
 	"DBManager/internal/shared/config"
 	"DBManager/internal/shared/dto"
+	cfgdto "DBManager/internal/shared/dto/config"
+	"DBManager/internal/shared/events"
+	"DBManager/internal/shared/jwtkeys"
+	"DBManager/internal/shared/mailer"
+	"DBManager/internal/shared/oidc"
+	"DBManager/internal/shared/passwords"
+	"DBManager/internal/shared/rbac"
 	"DBManager/internal/shared/utils"
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/google/uuid"
 	"log/slog"
-	"regexp"
 	"time"
 )
 
 type IAuth interface {
-	Authentication(ctx context.Context, creds *dto.SignInRequest, deviceInfo, ipAddress string) (*dto.TokenPair, error)
+	Authentication(ctx context.Context, creds *dto.SignInRequest, deviceInfo, ipAddress string) (*dto.TokenPair, *dto.MFAChallenge, error)
+	VerifyMFA(ctx context.Context, req *dto.VerifyMFARequest, deviceInfo, ipAddress string) (*dto.TokenPair, error)
 	Registration(ctx context.Context, creds *dto.SignUpRequest, deviceInfo, ipAddress string) (*dto.TokenPair, error)
 	LogOut(ctx context.Context, claims *dto.AccessToken) error
-	RefreshTokens(ctx context.Context, jti string) (*dto.TokenPair, error)
+	RefreshTokens(ctx context.Context, jti, ipAddress string) (*dto.TokenPair, error)
+	ChangeUserRole(ctx context.Context, userID int, role string) error
+	UnlockAccount(ctx context.Context, email string) error
+	ValidateAccessToken(ctx context.Context, tokenString string) (*dto.AccessToken, error)
+	JWKS() jwtkeys.JWKS
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	ChangePassword(ctx context.Context, claims *dto.AccessToken, req *dto.ChangePasswordRequest, deviceInfo, ipAddress string) (*dto.TokenPair, error)
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, email string) error
+	ListSessions(ctx context.Context, claims *dto.AccessToken) ([]dto.Session, error)
+	RevokeSession(ctx context.Context, claims *dto.AccessToken, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, claims *dto.AccessToken) (int, error)
+	EnrollTOTP(ctx context.Context, claims *dto.AccessToken) (*dto.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, claims *dto.AccessToken, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, claims *dto.AccessToken, req *dto.DisableTOTPRequest, ipAddress string) error
+	RegenerateRecoveryCodes(ctx context.Context, claims *dto.AccessToken, code string) ([]string, error)
+	CreateAPIKey(ctx context.Context, claims *dto.AccessToken, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, claims *dto.AccessToken, userID int) ([]dto.APIKey, error)
+	RevokeAPIKey(ctx context.Context, claims *dto.AccessToken, keyID int) error
+	ValidateAPIKey(ctx context.Context, rawKey, ipAddress string) (*dto.AccessToken, error)
+	OIDCLoginURL(ctx context.Context) (string, string, error)
+	OIDCCallback(ctx context.Context, code, state, deviceInfo, ipAddress string) (*dto.TokenPair, *dto.MFAChallenge, error)
 }
 
 type Auth struct {
-	repo    IAuthRepository
-	jwtRepo IJWTTokenRepository
+	repo      IAuthRepository
+	jwtRepo   IJWTTokenRepository
+	attempts  ILoginAttemptRepository
+	mailer    mailer.Mailer
+	events    events.Emitter
+	keys      *jwtkeys.KeySet
+	oidc      *oidc.Provider // nil - вход через внешнего провайдера выключен
+	passwords *passwords.Policy
+	hasher    passwords.Hasher
 }
 
-func NewAuth(repo IAuthRepository) *Auth {
-	return &Auth{repo: repo}
+func NewAuth(repo IAuthRepository, jwtRepo IJWTTokenRepository, attempts ILoginAttemptRepository, mailer mailer.Mailer, events events.Emitter, keys *jwtkeys.KeySet, oidcProvider *oidc.Provider, passwordPolicy *passwords.Policy, hasher passwords.Hasher) *Auth {
+	return &Auth{repo: repo, jwtRepo: jwtRepo, attempts: attempts, mailer: mailer, events: events, keys: keys, oidc: oidcProvider, passwords: passwordPolicy, hasher: hasher}
 }
 
-func (a *Auth) Authentication(ctx context.Context, creds *dto.SignInRequest, deviceInfo, ipAddress string) (*dto.TokenPair, error) {
-	// Проверяем, соответствует ли отправленный email формату.
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(creds.Email) {
-		return nil, errors2.InvalidEmailFormat
-	}
-	// Проверяем, соответствует ли отправленный пароль формату.
-	passwordRegex := regexp.MustCompile(`^(?=.*[A-Za-z])(?=.*\d)(?=.*[@$!%*#?&]).{8,}$`)
-	if passwordRegex.MatchString(creds.Password) {
-		return nil, errors2.InvalidPasswordFormat
+func (a *Auth) Authentication(ctx context.Context, creds *dto.SignInRequest, deviceInfo, ipAddress string) (*dto.TokenPair, *dto.MFAChallenge, error) {
+	// Формат email и пароля уже проверен по тегам validate у dto.SignInRequest.
+
+	// Защита от перебора: блокировка аккаунта, лимит попыток с IP и задержка между попытками.
+	lockoutEmail := normalizeEmail(creds.Email)
+	attempt, err := a.reserveLoginAttempt(ctx, lockoutEmail, ipAddress)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Получаем ID из БД по email.
-	userID, err := a.repo.GetIDByEmail(ctx, creds.Email)
+	userID, err := a.checkCredentials(ctx, creds)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, errors2.UserNotExist) || errors.Is(err, errors2.PasswordWrong) {
+			// Попытки к несуществующим аккаунтам тоже считаются, иначе по блокировке можно определить, есть ли аккаунт.
+			if err := a.registerLoginFailure(ctx, lockoutEmail, ipAddress, attempt); err != nil {
+				return nil, nil, err
+			}
+		} else {
+			a.releaseLoginAttempt(ctx, lockoutEmail, ipAddress, attempt)
+		}
+		return nil, nil, err
 	}
 
-	// Получаем Hash из БД по ID.
-	hash, err := a.repo.GetHashByID(ctx, userID)
+	// Получаем пользователя, чтобы вшить его роль в Access Token.
+	user, err := a.repo.GetUserByID(ctx, userID)
 	if err != nil {
-		return nil, err
+		a.releaseLoginAttempt(ctx, lockoutEmail, ipAddress, attempt)
+		return nil, nil, err
 	}
 
-	// Проверяю, подходит ли пароль, если нет - возвращаю ошибку, что пароль недействителен.
-	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(creds.Password)); err != nil {
-		return nil, err
+	// При строгой политике вход без подтверждённого email запрещён.
+	if !user.EmailVerified && config.AuthConfig().UnverifiedLogin == cfgdto.UnverifiedLoginDeny {
+		a.releaseLoginAttempt(ctx, lockoutEmail, ipAddress, attempt)
+		return nil, nil, errors2.ErrEmailNotVerified
 	}
 
-	// Проверяем, есть ли у пользователя активный(ые) Refresh Token, если да - отзываем.
-	countRevoke, err := a.jwtRepo.RevokeActiveRefreshTokens(ctx, userID)
+	// С подключённой второй ступенью вместо токенов выдаётся вызов, который обменивается на них в VerifyMFA.
+	mfaEnabled, err := a.mfaEnabled(ctx, user.ID)
 	if err != nil {
-		if !errors.Is(err, repository.RecordNotFound) {
+		a.releaseLoginAttempt(ctx, lockoutEmail, ipAddress, attempt)
+		return nil, nil, err
+	}
+	if mfaEnabled {
+		// Вход ещё не завершён: счётчик неудач сбросит VerifyMFA после верного кода. Иначе знающий пароль
+		// обнулял бы его повторным входом перед каждой серией неверных кодов.
+		a.releaseLoginAttempt(ctx, lockoutEmail, ipAddress, attempt)
+		challenge, err := a.createMFAChallenge(ctx, user.ID)
+		return nil, challenge, err
+	}
+
+	if err := a.attempts.ResetLoginFailures(ctx, lockoutEmail, ipAddress, attempt.ID); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := a.startSession(ctx, user, deviceInfo, ipAddress, false)
+	return tokens, nil, err
+}
+
+// startSession - начинает новую сессию пользователя после успешного входа.
+func (a *Auth) startSession(ctx context.Context, user *dto.User, deviceInfo, ipAddress string, mfa bool) (*dto.TokenPair, error) {
+	// В режиме единственной сессии вход на новом устройстве завершает все остальные.
+	if config.AuthConfig().SingleSession {
+		countRevoke, err := a.revokeSessions(ctx, user.ID, "")
+		if err != nil {
 			return nil, err
 		}
-		slog.Info("У пользователя id:", userID, " было отозвано ", countRevoke, " Refresh токен(ов).")
+		slog.Info("Завершены сессии пользователя", "user_id", user.ID, "count", countRevoke)
 	}
 
-	// Генерируем Access Token.
-	newAccessToken, _, err := utils.GenerateAccessToken(userID, config.TokenConfig().AccessTTL, []byte(config.TokenConfig().AccessSecret))
+	// Выпускаем пару токенов для нового сеанса.
+	return a.issueTokens(ctx, user, deviceInfo, ipAddress, nil, mfa)
+}
+
+// checkCredentials - проверяет email и пароль и возвращает ID пользователя.
+func (a *Auth) checkCredentials(ctx context.Context, creds *dto.SignInRequest) (int, error) {
+	// Получаем ID из БД по email.
+	userID, err := a.repo.GetIDByEmail(ctx, creds.Email)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, repository.RecordNotFound) {
+			return 0, errors2.UserNotExist
+		}
+		return 0, err
 	}
 
-	// Генерируем Refresh Token.
-	newRefreshToken, hashRefreshToken := utils.GenerateRefreshToken()
+	// Проверяю, подходит ли пароль, если нет - возвращаю ошибку, что пароль недействителен.
+	hash, err := a.verifyPassword(ctx, userID, creds.Password)
+	if err != nil {
+		return 0, err
+	}
 
-	// Помещаем Refresh Token в БД.
-	if err := a.jwtRepo.CreateRefreshToken(ctx, &dto.RefreshToken{
-		UserID:     userID,
-		TokenHash:  hashRefreshToken,
-		DeviceInfo: deviceInfo,
-		IPAddress:  ipAddress,
-		ExpiresAt:  time.Now().Add(config.TokenConfig().RefreshTTL),
-		CreatedAt:  time.Now(),
-		IsRevoked:  false,
-	}); err != nil {
-		return nil, err
+	// Хэш прежнего алгоритма или со слабыми параметрами пересчитываем, пока пароль известен.
+	if a.hasher.NeedsRehash(hash) {
+		a.rehashPassword(ctx, userID, creds.Password)
 	}
 
-	// Возвращаем пару токенов.
-	return &dto.TokenPair{
-		RefreshToken: newRefreshToken,
-		AccessToken:  newAccessToken,
-	}, nil
+	return userID, nil
 }
 
-func (a *Auth) Registration(ctx context.Context, creds *dto.SignUpRequest, deviceInfo, ipAddress string) (*dto.TokenPair, error) {
-	// Проверяем, соответствует ли отправленный email формату.
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(creds.Email) {
-		return nil, errors2.InvalidEmailFormat
+// verifyPassword - проверяет пароль пользователя по хэшу из БД и возвращает хэш. Неверный пароль - PasswordWrong.
+func (a *Auth) verifyPassword(ctx context.Context, userID int, password string) (string, error) {
+	hash, err := a.repo.GetHashByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	ok, err := a.hasher.Verify(password, hash)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errors2.PasswordWrong
+	}
+	return hash, nil
+}
+
+// rehashPassword - пересчитывает хэш пароля текущим алгоритмом с текущими параметрами.
+// Ошибка не мешает входу: хэш пересчитается при следующем.
+func (a *Auth) rehashPassword(ctx context.Context, userID int, password string) {
+	hash, err := a.hasher.Hash(password)
+	if err == nil {
+		err = a.repo.ChangeHashDB(ctx, userID, hash)
 	}
-	// Проверяем, соответствует ли отправленный пароль формату.
-	passwordRegex := regexp.MustCompile(`^(?=.*[A-Za-z])(?=.*\d)(?=.*[@$!%*#?&]).{8,}$`)
-	if passwordRegex.MatchString(creds.Password) {
-		return nil, errors2.InvalidPasswordFormat
+	if err != nil {
+		slog.Warn("Не удалось пересчитать хэш пароля", "user_id", userID, "error", err)
+		return
 	}
+	slog.Info("Хэш пароля пересчитан текущим алгоритмом", "user_id", userID)
+}
+
+func (a *Auth) Registration(ctx context.Context, creds *dto.SignUpRequest, deviceInfo, ipAddress string) (*dto.TokenPair, error) {
+	// Формат email и соответствие пароля политике уже проверены по тегам validate у dto.SignUpRequest.
 
 	// Проверяем существует ли пользователь с данным email в БД.
 	_, err := a.repo.GetIDByEmail(ctx, creds.Email)
@@ -123,8 +210,17 @@ func (a *Auth) Registration(ctx context.Context, creds *dto.SignUpRequest, devic
 		return nil, errors2.EmailAlreadyExist
 	}
 
-	// Генерируем хэш из пароля, с константным значением 10 - дефолт.
-	hash, err := bcrypt.GenerateFromPassword([]byte(creds.Password), bcrypt.DefaultCost)
+	// Пароль не должен содержать email и имя пользователя и встречаться в утечках.
+	if err := a.passwords.Check(creds.Password, &dto.User{
+		FirstName: creds.FirstName,
+		LastName:  creds.LastName,
+		Email:     creds.Email,
+	}); err != nil {
+		return nil, passwordError("password", err)
+	}
+
+	// Генерируем хэш из пароля алгоритмом из конфигурации.
+	hash, err := a.hasher.Hash(creds.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +230,8 @@ func (a *Auth) Registration(ctx context.Context, creds *dto.SignUpRequest, devic
 	newUser.FirstName = creds.FirstName
 	newUser.LastName = creds.LastName
 	newUser.Email = creds.Email
-	newUser.Hash = string(hash) // передаём в БД хэш вместо пароля.
+	newUser.Hash = hash // передаём в БД хэш вместо пароля.
+	newUser.Role = string(rbac.DefaultRole)
 	newUser.CreatedAt = time.Now()
 	newUser.UpdatedAt = time.Now()
 
@@ -143,8 +240,38 @@ func (a *Auth) Registration(ctx context.Context, creds *dto.SignUpRequest, devic
 		return nil, err
 	}
 
-	// Генерируем Access Token.
-	newAccessToken, _, err := utils.GenerateAccessToken(newUser.ID, config.TokenConfig().AccessTTL, []byte(config.TokenConfig().AccessSecret))
+	// Отправляем письмо для подтверждения email. Ошибка отправки не отменяет регистрацию -
+	// письмо можно запросить повторно.
+	if err := a.sendVerification(ctx, &newUser); err != nil {
+		slog.Error("Не удалось отправить письмо подтверждения email", "user_id", newUser.ID, "error", err)
+	}
+
+	if config.AuthConfig().UnverifiedLogin == cfgdto.UnverifiedLoginDeny {
+		return nil, errors2.ErrEmailNotVerified
+	}
+
+	// Выпускаем пару токенов для первого сеанса.
+	return a.issueTokens(ctx, &newUser, deviceInfo, ipAddress, nil, false)
+}
+
+// issueTokens - выпускает пару токенов и помещает Refresh Token в БД.
+// Без parent начинается новое семейство (сессия), иначе токен продолжает семейство parent.
+// mfa отмечает, что при входе в сессию была пройдена вторая ступень.
+func (a *Auth) issueTokens(ctx context.Context, user *dto.User, deviceInfo, ipAddress string, parent *dto.RefreshToken, mfa bool) (*dto.TokenPair, error) {
+	familyID := uuid.NewString()
+	var parentID *int
+	if parent != nil {
+		familyID = parent.FamilyID
+		parentID = &parent.ID
+	}
+
+	// Генерируем Access Token с действующей ролью пользователя, id сессии - семейство токенов.
+	role := a.effectiveRole(user)
+	signingKey, err := a.keys.Signing(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	newAccessToken, accessJti, err := utils.GenerateAccessToken(user.ID, string(role), familyID, mfa, config.TokenConfig().AccessTTL, signingKey)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +281,11 @@ func (a *Auth) Registration(ctx context.Context, creds *dto.SignUpRequest, devic
 
 	// Помещаем Refresh Token в БД.
 	if err := a.jwtRepo.CreateRefreshToken(ctx, &dto.RefreshToken{
-		UserID:     newUser.ID,
+		UserID:     user.ID,
+		FamilyID:   familyID,
+		ParentID:   parentID,
+		AccessJti:  accessJti,
+		MFA:        mfa,
 		TokenHash:  hashRefreshToken,
 		DeviceInfo: deviceInfo,
 		IPAddress:  ipAddress,
@@ -172,15 +303,16 @@ func (a *Auth) Registration(ctx context.Context, creds *dto.SignUpRequest, devic
 	}, nil
 }
 
+// LogOut - завершает текущую сессию: отзывает её Refresh токены и блокирует выданные в ней Access токены.
+// Сессии на других устройствах продолжают работать.
 func (a *Auth) LogOut(ctx context.Context, claims *dto.AccessToken) error {
-	countRevoke, err := a.jwtRepo.RevokeActiveRefreshTokens(ctx, claims.UserID)
+	countRevoke, err := a.revokeFamily(ctx, claims.UserID, claims.Sid)
 	if err != nil {
-		if !errors.Is(err, repository.RecordNotFound) {
-			return err
-		}
-		slog.Info("У пользователя id:", claims.UserID, " было отозвано ", countRevoke, " Refresh токен(ов).")
+		return err
 	}
+	slog.Info("Сессия пользователя завершена", "user_id", claims.UserID, "session_id", claims.Sid, "count", countRevoke)
 
+	// Токен запроса блокируем явно, даже если его сессия уже была отозвана.
 	timeLife := claims.ExpiresAt.Sub(time.Now())
 	if err := a.jwtRepo.AddAccessToBlackList(ctx, claims.Jti, timeLife); err != nil {
 		return err
@@ -190,57 +322,152 @@ func (a *Auth) LogOut(ctx context.Context, claims *dto.AccessToken) error {
 }
 
 // RefreshTokens обновляет Refresh & Access токены.
-func (a *Auth) RefreshTokens(ctx context.Context, jti string) (*dto.TokenPair, error) {
+// Проверка, отзыв старого и выпуск нового токена выполняются в одной транзакции под блокировкой строки,
+// поэтому из параллельных запросов с одним токеном новую пару получит только один.
+// Предъявление уже ротированного токена означает, что им воспользовался кто-то ещё:
+// в этом случае отзывается всё семейство токенов и блокируются выданные по нему Access токены.
+func (a *Auth) RefreshTokens(ctx context.Context, jti, ipAddress string) (*dto.TokenPair, error) {
 	// 1. Хэшируем токен.
-	hash := sha256.Sum256([]byte(jti))
-	tokenHash := hex.EncodeToString(hash[:])
+	tokenHash := utils.HashToken(jti)
+
+	var tokenFromDB *dto.RefreshToken
+	var tokens *dto.TokenPair
+
+	err := a.jwtRepo.WithTx(ctx, func(ctx context.Context) error {
+		// 2. Получаем информацию о токене из БД по хэшу и блокируем его до конца транзакции.
+		var err error
+		tokenFromDB, err = a.jwtRepo.LockRefreshTokenByHash(ctx, tokenHash)
+		if err != nil {
+			if errors.Is(err, repository.RecordNotFound) {
+				return errors2.ErrRefreshTokenInvalid
+			}
+			return err
+		}
+
+		// 3. Проверяем, не отозван ли токен
+		if tokenFromDB.IsRevoked {
+			if tokenFromDB.RotatedAt != nil {
+				return errors2.ErrRefreshTokenReused
+			}
+			return errors2.ErrRefreshTokenRevoked
+		}
 
-	// 2. Получаем информацию о токене из БД по хэшу.
-	tokenFromDB, err := a.jwtRepo.GetRefreshTokenByHash(ctx, tokenHash)
+		// 4. Проверяем срок действия
+		if time.Now().After(tokenFromDB.ExpiresAt) {
+			return errors2.ErrRefreshTokenExpired
+		}
+
+		// 5. Отзываем старый refresh токен, отмечая его ротированным.
+		if err := a.jwtRepo.MarkRefreshTokenRotated(ctx, tokenHash); err != nil {
+			if errors.Is(err, repository.RecordNotFound) {
+				return errors2.ErrRefreshTokenRevoked
+			}
+			return err
+		}
+
+		// 6. Получаем пользователя, чтобы вшить в Access Token его актуальную роль.
+		user, err := a.repo.GetUserByID(ctx, tokenFromDB.UserID)
+		if err != nil {
+			return err
+		}
+
+		// 7. Выпускаем новую пару токенов в том же семействе.
+		tokens, err = a.issueTokens(ctx, user, tokenFromDB.DeviceInfo, ipAddress, tokenFromDB, tokenFromDB.MFA)
+		return err
+	})
 	if err != nil {
+		// Отзыв семейства выполняется после отката транзакции, иначе он был бы отменён вместе с ней.
+		if errors.Is(err, errors2.ErrRefreshTokenReused) {
+			a.handleRefreshTokenReuse(ctx, tokenFromDB, ipAddress)
+		}
 		return nil, err
 	}
 
-	// 3. Проверяем, не отозван ли токен
-	if tokenFromDB.IsRevoked {
-		return nil, errors2.ErrRefreshTokenRevoked
-	}
+	return tokens, nil
+}
 
-	// 4. Проверяем срок действия
-	if time.Now().After(tokenFromDB.ExpiresAt) {
-		return nil, errors2.ErrRefreshTokenExpired
+// handleRefreshTokenReuse - реакция на повторное предъявление ротированного токена: отзыв семейства,
+// блокировка Access токенов и событие безопасности. Ошибки только логируются - клиент в любом случае получает отказ.
+func (a *Auth) handleRefreshTokenReuse(ctx context.Context, token *dto.RefreshToken, ipAddress string) {
+	revoked, err := a.revokeFamily(ctx, token.UserID, token.FamilyID)
+	if err != nil {
+		slog.Error("Не удалось отозвать семейство refresh токенов", "family_id", token.FamilyID, "error", err)
 	}
 
-	// 5. Отзываем старый refresh токен
-	if err := a.jwtRepo.RevokeRefreshToken(ctx, tokenHash); err != nil {
-		return nil, err
+	a.events.Emit(ctx, events.SecurityEvent{
+		Type:      events.RefreshTokenReuse,
+		UserID:    token.UserID,
+		IPAddress: ipAddress,
+		Details: map[string]any{
+			"family_id":      token.FamilyID,
+			"token_id":       token.ID,
+			"rotated_at":     token.RotatedAt,
+			"revoked_tokens": revoked,
+		},
+	})
+}
+
+// revokeFamily - отзывает все токены семейства и блокирует ещё не истёкшие Access токены, выданные вместе с ними.
+func (a *Auth) revokeFamily(ctx context.Context, userID int, familyID string) (int, error) {
+	revoked, err := a.jwtRepo.RevokeRefreshTokenFamily(ctx, userID, familyID)
+	if err != nil && !errors.Is(err, repository.RecordNotFound) {
+		return 0, err
 	}
 
-	// 6. Генерируем Access Token.
-	newAccessToken, _, err := utils.GenerateAccessToken(tokenFromDB.UserID, config.TokenConfig().AccessTTL, []byte(config.TokenConfig().AccessSecret))
+	family, err := a.jwtRepo.ListRefreshTokenFamily(ctx, userID, familyID)
 	if err != nil {
-		return nil, err
+		return revoked, err
 	}
 
-	// 7. Генерируем Refresh Token.
-	newRefreshToken, hashRefreshToken := utils.GenerateRefreshToken()
+	accessTTL := config.TokenConfig().AccessTTL
+	for _, t := range family {
+		remaining := time.Until(t.CreatedAt.Add(accessTTL))
+		if t.AccessJti == "" || remaining <= 0 {
+			continue
+		}
+		if err := a.jwtRepo.AddAccessToBlackList(ctx, t.AccessJti, remaining); err != nil {
+			return revoked, err
+		}
+	}
 
-	// 8. Помещаем Refresh Token в БД.
-	if err := a.jwtRepo.CreateRefreshToken(ctx, &dto.RefreshToken{
-		UserID:     tokenFromDB.UserID,
-		TokenHash:  hashRefreshToken,
-		DeviceInfo: tokenFromDB.DeviceInfo,
-		IPAddress:  tokenFromDB.IPAddress,
-		ExpiresAt:  time.Now().Add(config.TokenConfig().RefreshTTL),
-		CreatedAt:  time.Now(),
-		IsRevoked:  false,
-	}); err != nil {
+	return revoked, nil
+}
+
+// ChangeUserRole - назначает пользователю роль. Новая роль попадёт в Access Token при следующем обновлении токенов.
+func (a *Auth) ChangeUserRole(ctx context.Context, userID int, role string) error {
+	if !rbac.IsValid(rbac.Role(role)) {
+		return errors2.ErrInvalidRole
+	}
+
+	if err := a.repo.SetUserRole(ctx, userID, role, rbac.Role(role) == rbac.RoleAdmin); err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return errors2.UserNotExist
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ValidateAccessToken - проверяет подпись и срок действия Access Token, а также что он не в чёрном списке.
+func (a *Auth) ValidateAccessToken(ctx context.Context, tokenString string) (*dto.AccessToken, error) {
+	claims, err := utils.ValidateAccessToken(tokenString, a.keys)
+	if err != nil {
+		return nil, errors2.ErrAccessTokenInvalid
+	}
+
+	blocked, err := a.jwtRepo.IsAccessBlocked(ctx, claims.Jti)
+	if err != nil {
 		return nil, err
 	}
+	if blocked {
+		return nil, errors2.ErrAccessTokenRevoked
+	}
 
-	// 9. Возвращаем пару токенов.
-	return &dto.TokenPair{
-		RefreshToken: newRefreshToken,
-		AccessToken:  newAccessToken,
-	}, nil
+	return claims, nil
+}
+
+// JWKS - публичные ключи для проверки Access токенов другими сервисами.
+func (a *Auth) JWKS() jwtkeys.JWKS {
+	return a.keys.JWKS(time.Now())
 }
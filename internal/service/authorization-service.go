@@ -0,0 +1,25 @@
+package service
+
+import (
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/rbac"
+	"slices"
+)
+
+// Authorize - проверяет, что токен даёт право permission. Для прав на изменение остатков
+// при включённом MFA_ENFORCE требуется, чтобы при входе была пройдена вторая ступень.
+func Authorize(claims *dto.AccessToken, permission rbac.Permission) error {
+	if !rbac.Can(rbac.Role(claims.Role), permission) {
+		return errors2.ErrForbidden
+	}
+	if config.AuthConfig().MFAEnforced && rbac.RequiresMFA(permission) && !claims.MFA {
+		return errors2.ErrMFARequired
+	}
+	// Запрос по API ключу дополнительно ограничен правами, выданными ключу.
+	if claims.APIKeyID != 0 && !slices.Contains(claims.Scopes, string(permission)) {
+		return errors2.ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"DBManager/internal/repository"
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/dto"
+	cfgdto "DBManager/internal/shared/dto/config"
+	"DBManager/internal/shared/mailer"
+	"DBManager/internal/shared/rbac"
+	"DBManager/internal/shared/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+// VerifyEmail - подтверждает email по одноразовому токену из письма.
+func (a *Auth) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := a.repo.ConsumeOneTimeToken(ctx, dto.TokenPurposeEmailVerification, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return errors2.ErrVerifyTokenInvalid
+		}
+		return err
+	}
+
+	if err := a.repo.SetEmailVerified(ctx, userID); err != nil {
+		return err
+	}
+
+	slog.Info("Email пользователя подтверждён", "user_id", userID)
+	return nil
+}
+
+// ResendVerification - повторно отправляет письмо подтверждения, не чаще раза в интервал из конфигурации.
+// Для несуществующих и уже подтверждённых email, а также до истечения интервала ничего не делает
+// и не возвращает ошибку: по ответу нельзя узнать, есть ли неподтверждённая учётная запись с этим email.
+func (a *Auth) ResendVerification(ctx context.Context, email string) error {
+	userID, err := a.repo.GetIDByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	user, err := a.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	lastSentAt, err := a.repo.LastOneTimeTokenAt(ctx, userID, dto.TokenPurposeEmailVerification)
+	if err != nil && !errors.Is(err, repository.RecordNotFound) {
+		return err
+	}
+	if err == nil && time.Since(lastSentAt) < config.AuthConfig().VerificationResend {
+		return nil
+	}
+
+	return a.sendVerification(ctx, user)
+}
+
+// sendVerification - выпускает новый токен подтверждения email и отправляет его пользователю.
+func (a *Auth) sendVerification(ctx context.Context, user *dto.User) error {
+	// Действует только последняя ссылка - предыдущие гасим.
+	if err := a.repo.InvalidateOneTimeTokens(ctx, user.ID, dto.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	token, tokenHash := utils.GenerateOpaqueToken()
+	ttl := config.TokenConfig().VerifyTTL
+
+	if err := a.repo.CreateOneTimeToken(ctx, &dto.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   dto.TokenPurposeEmailVerification,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", config.MailerConfig().PublicURL, url.QueryEscape(token))
+
+	return a.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Подтверждение email",
+		Body:    fmt.Sprintf("Для подтверждения email перейдите по ссылке: %s\n\nСсылка действует %d ч.", link, int(ttl.Hours())),
+	})
+}
+
+// effectiveRole - роль для Access Token. При политике limit пользователь без подтверждённого email
+// получает права только на чтение.
+func (a *Auth) effectiveRole(user *dto.User) rbac.Role {
+	if !user.EmailVerified && config.AuthConfig().UnverifiedLogin == cfgdto.UnverifiedLoginLimit {
+		return rbac.RoleReadOnly
+	}
+	return rbac.Resolve(user.Role, user.IsAdmin)
+}
@@ -1,16 +1,86 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
-	UserNotExist          = errors.New("пользователя с данным Email не существует")
-	PasswordWrong         = errors.New("неверный пароль")
-	EmailAlreadyExist     = errors.New("данный email уже существует")
-	InvalidEmailFormat    = errors.New("некорректный формат email")
-	InvalidPasswordFormat = errors.New("некорректный формат пароля. Пароль должен содержать не менее 8 символов, содержать номер, букву и специальный символ")
+	UserNotExist         = errors.New("пользователя с данным Email не существует")
+	PasswordWrong        = errors.New("неверный пароль")
+	EmailAlreadyExist    = errors.New("данный email уже существует")
+	ErrInvalidRole       = errors.New("неизвестная роль пользователя")
+	ErrForbidden         = errors.New("недостаточно прав для выполнения действия")
+	ErrPasswordUnchanged = errors.New("новый пароль должен отличаться от текущего")
+
+	ErrEmailNotVerified = errors.New("email не подтверждён. Перейдите по ссылке из письма")
+
+	ErrTooManyLoginAttempts = errors.New("слишком много неудачных попыток входа, повторите позже")
+	ErrAccountLocked        = errors.New("аккаунт временно заблокирован из-за неудачных попыток входа")
+
+	ErrMFAAlreadyEnabled   = errors.New("двухфакторная аутентификация уже подключена")
+	ErrMFANotEnabled       = errors.New("двухфакторная аутентификация не подключена")
+	ErrMFACodeInvalid      = errors.New("неверный код подтверждения")
+	ErrMFAChallengeInvalid = errors.New("сессия входа истекла, войдите заново")
+	ErrMFARequired         = errors.New("для этого действия необходимо войти с двухфакторной аутентификацией")
+
+	ErrAPIKeyInvalid         = errors.New("API ключ недействителен, отозван или истёк")
+	ErrAPIKeyNotFound        = errors.New("API ключ не найден или уже отозван")
+	ErrInvalidAPIKeyFormat   = errors.New("некорректные данные API ключа. Название и права обязательны, срок действия - в будущем")
+	ErrAPIKeyScopeNotAllowed = errors.New("API ключ не может получить право, которого нет у роли владельца")
+	ErrSessionRequired       = errors.New("действие доступно только при входе по паролю, а не по API ключу")
+
+	ErrOIDCDisabled         = errors.New("вход через внешнего провайдера не настроен")
+	ErrOIDCStateInvalid     = errors.New("сессия входа через провайдера истекла, начните вход заново")
+	ErrOIDCLoginFailed      = errors.New("провайдер не подтвердил вход")
+	ErrOIDCEmailNotVerified = errors.New("провайдер не подтвердил email пользователя")
+	ErrOIDCUserNotFound     = errors.New("пользователь с данным email не зарегистрирован, обратитесь к администратору")
 )
 
+// RetryAfter - ошибка, после которой запрос можно повторить не раньше чем через After.
+// Оборачивает сентинел, поэтому errors.Is продолжает работать.
+type RetryAfter struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryAfter) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfter) Unwrap() error {
+	return e.Err
+}
+
 var (
 	ErrRefreshTokenRevoked = errors.New("данный refresh token был отозван")
 	ErrRefreshTokenExpired = errors.New("срок действия данного refresh token истёк")
+	ErrRefreshTokenInvalid = errors.New("refresh token не найден")
+	ErrRefreshTokenReused  = errors.New("данный refresh token уже был использован, все сессии устройства завершены")
+	ErrAccessTokenInvalid  = errors.New("access token недействителен или истёк")
+	ErrAccessTokenRevoked  = errors.New("данный access token был отозван")
+	ErrSessionNotFound     = errors.New("сессия не найдена или уже завершена")
+	ErrResetTokenInvalid   = errors.New("ссылка для сброса пароля недействительна или устарела")
+	ErrVerifyTokenInvalid  = errors.New("ссылка для подтверждения email недействительна или устарела")
+)
+
+var (
+	ErrItemNotFound      = errors.New("товар не найден")
+	ErrSKUAlreadyExist   = errors.New("товар с данным артикулом уже существует")
+	ErrInvalidItemFormat = errors.New("некорректные данные товара. Артикул, название и единица измерения обязательны")
+	ErrItemInUse         = errors.New("по товару есть остатки или движения, удалить его нельзя")
+)
+
+var (
+	ErrWarehouseNotFound         = errors.New("склад не найден")
+	ErrLocationNotFound          = errors.New("место хранения не найдено")
+	ErrWarehouseCodeAlreadyExist = errors.New("склад с данным кодом уже существует")
+	ErrLocationCodeAlreadyExist  = errors.New("место хранения с данным кодом уже существует на складе")
+	ErrInvalidWarehouseFormat    = errors.New("некорректные данные склада. Код и название обязательны")
+	ErrInvalidLocationFormat     = errors.New("некорректные данные места хранения. Склад и код обязательны")
+)
+
+var (
+	ErrInvalidMovementFormat = errors.New("некорректные данные движения. Проверьте тип, количество и места хранения")
+	ErrInsufficientStock     = errors.New("недостаточно товара в месте хранения для списания")
 )
@@ -7,20 +7,97 @@ import (
 )
 
 type IAuthRepository interface {
+	ITransactor
+
 	GetIDByEmail(ctx context.Context, email string) (int, error)
 	ChangeHashDB(ctx context.Context, userID int, hash string) error
 	GetHashByID(ctx context.Context, userID int) (string, error)
+	GetUserByID(ctx context.Context, userID int) (*dto.User, error)
+	SetUserRole(ctx context.Context, userID int, role string, isAdmin bool) error
+	SetEmailVerified(ctx context.Context, userID int) error
+	GetPasswordHistory(ctx context.Context, userID, limit int) ([]string, error)
+	PushPasswordHistory(ctx context.Context, userID int, hash string, keep int) error
+
+	CreateOneTimeToken(ctx context.Context, token *dto.OneTimeToken) error
+	ConsumeOneTimeToken(ctx context.Context, purpose, hash string) (int, error)
+	InvalidateOneTimeTokens(ctx context.Context, userID int, purpose string) error
+	GetOneTimeToken(ctx context.Context, purpose, hash string) (*dto.OneTimeToken, error)
+	LastOneTimeTokenAt(ctx context.Context, userID int, purpose string) (time.Time, error)
 	AddUser(ctx context.Context, repo *dto.User) error
+
+	GetTOTP(ctx context.Context, userID int) (*dto.UserTOTP, error)
+	SaveTOTPSecret(ctx context.Context, userID int, secret string) error
+	ConfirmTOTP(ctx context.Context, userID int) error
+	DeleteTOTP(ctx context.Context, userID int) error
+	UseTOTPStep(ctx context.Context, userID int, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int, hashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userID int, hash string) error
+
+	CreateAPIKey(ctx context.Context, key *dto.APIKey) error
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*dto.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID int) ([]dto.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID, userID int) error
+	TouchAPIKey(ctx context.Context, keyID int, ipAddress string) error
+
+	GetUserIdentity(ctx context.Context, issuer, subject string) (*dto.UserIdentity, error)
+	CreateUserIdentity(ctx context.Context, identity *dto.UserIdentity) error
+	TouchUserIdentity(ctx context.Context, identityID int, email string) error
+	SaveOIDCState(ctx context.Context, stateHash string, state *dto.OIDCState, ttl time.Duration) error
+	ConsumeOIDCState(ctx context.Context, stateHash string) (*dto.OIDCState, error)
 }
 
 type IManagerRepository interface {
-	DeleteRecord(ctx context.Context, recordID int) error
+	ITransactor
+
+	CreateItem(ctx context.Context, item *dto.Item) error
+	GetItemByID(ctx context.Context, itemID int) (*dto.Item, error)
+	GetItemBySKU(ctx context.Context, sku string) (*dto.Item, error)
+	ListItems(ctx context.Context, filter *dto.ItemFilter) ([]dto.Item, error)
+	UpdateItem(ctx context.Context, item *dto.Item) error
+	DeleteItem(ctx context.Context, itemID int) error
+
+	CreateWarehouse(ctx context.Context, warehouse *dto.Warehouse) error
+	GetWarehouseByID(ctx context.Context, warehouseID int) (*dto.Warehouse, error)
+	ListWarehouses(ctx context.Context) ([]dto.Warehouse, error)
+	CreateLocation(ctx context.Context, location *dto.Location) error
+	GetLocationByID(ctx context.Context, locationID int) (*dto.Location, error)
+	ListLocations(ctx context.Context, warehouseID int) ([]dto.Location, error)
+	GetItemStock(ctx context.Context, itemID, warehouseID int) ([]dto.LocationStock, error)
+
+	CreateMovement(ctx context.Context, movement *dto.StockMovement) error
+	ListMovements(ctx context.Context, filter *dto.MovementFilter) ([]dto.StockMovement, error)
+	GetLedgerBalances(ctx context.Context, itemID int) ([]dto.StockBalance, error)
+	GetStockLevels(ctx context.Context, itemID int) ([]dto.StockLevel, error)
+	LockStockLevels(ctx context.Context, itemID int) ([]dto.StockLevel, error)
+	SetStockLevel(ctx context.Context, level *dto.StockLevel, movement *dto.StockMovement) (bool, error)
+}
+
+// ITransactor - репозиторий, умеющий выполнять группу своих вызовов в одной транзакции.
+// Методы репозиториев, вызванные с контекстом, переданным в fn, работают внутри неё.
+type ITransactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type IJWTTokenRepository interface {
+	ITransactor
+
 	CreateRefreshToken(ctx context.Context, token *dto.RefreshToken) error
 	GetRefreshTokenByHash(ctx context.Context, hash string) (*dto.RefreshToken, error)
+	LockRefreshTokenByHash(ctx context.Context, hash string) (*dto.RefreshToken, error)
 	RevokeActiveRefreshTokens(ctx context.Context, userID int) (int, error)
 	RevokeRefreshToken(ctx context.Context, hash string) error
+	ListActiveRefreshTokens(ctx context.Context, userID int) ([]dto.RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, userID int, familyID string) (int, error)
+	ListRefreshTokenFamily(ctx context.Context, userID int, familyID string) ([]dto.RefreshToken, error)
+	MarkRefreshTokenRotated(ctx context.Context, hash string) error
 	AddAccessToBlackList(ctx context.Context, jti string, expiresAt time.Duration) error
+	IsAccessBlocked(ctx context.Context, jti string) (bool, error)
+}
+
+type ILoginAttemptRepository interface {
+	ReserveLoginAttempt(ctx context.Context, email, ip string, limits *dto.LoginAttemptLimits) (*dto.LoginAttempt, error)
+	ReleaseLoginAttempt(ctx context.Context, email, ip, attemptID string) error
+	ResetLoginFailures(ctx context.Context, email, ip, attemptID string) error
+	LockAccount(ctx context.Context, email string, ttl time.Duration) error
+	UnlockAccount(ctx context.Context, email string) error
 }
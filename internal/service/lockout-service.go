@@ -0,0 +1,142 @@
+package service
+
+import (
+	"DBManager/internal/repository"
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/events"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// reserveLoginAttempt - резервирует попытку входа в аккаунт email с адреса ip до проверки пароля или кода.
+// Отказывает, если аккаунт заблокирован, превышен лимит неудачных попыток с IP или не выдержана прогрессивная задержка.
+// Проверка и резервирование атомарны, поэтому параллельные запросы не получают лишних попыток.
+func (a *Auth) reserveLoginAttempt(ctx context.Context, email, ip string) (*dto.LoginAttempt, error) {
+	cfg := config.LockoutConfig()
+
+	limits := &dto.LoginAttemptLimits{
+		Window:        cfg.Window,
+		MaxFailures:   cfg.MaxFailures,
+		MaxIPFailures: cfg.MaxIPFailures,
+		LockoutFor:    cfg.LockoutFor,
+		Delays:        make([]time.Duration, cfg.MaxFailures),
+	}
+	for i := range limits.Delays {
+		limits.Delays[i] = loginDelay(i)
+	}
+
+	attempt, err := a.attempts.ReserveLoginAttempt(ctx, email, ip, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	switch attempt.Denied {
+	case "":
+		return attempt, nil
+	case dto.LoginDeniedLocked:
+		return nil, &errors2.RetryAfter{Err: errors2.ErrAccountLocked, After: attempt.RetryAfter}
+	default:
+		// С одного IP перебирают пароли к разным аккаунтам - ждать придётся, пока старые попытки не выйдут из окна.
+		return nil, &errors2.RetryAfter{Err: errors2.ErrTooManyLoginAttempts, After: attempt.RetryAfter}
+	}
+}
+
+// registerLoginFailure - попытка не удалась: она уже учтена при резервировании, остаётся заблокировать аккаунт,
+// если попыток стало слишком много.
+func (a *Auth) registerLoginFailure(ctx context.Context, email, ip string, attempt *dto.LoginAttempt) error {
+	cfg := config.LockoutConfig()
+
+	if attempt.ByEmail < cfg.MaxFailures {
+		return nil
+	}
+
+	if err := a.attempts.LockAccount(ctx, email, cfg.LockoutFor); err != nil {
+		return err
+	}
+
+	a.events.Emit(ctx, events.SecurityEvent{
+		Type:      events.AccountLocked,
+		IPAddress: ip,
+		Details: map[string]any{
+			"email":    email,
+			"failures": attempt.ByEmail,
+			"until":    time.Now().Add(cfg.LockoutFor),
+		},
+	})
+	return nil
+}
+
+// releaseLoginAttempt - снимает попытку, которая не дошла до проверки пароля или кода из-за внутренней ошибки:
+// сбой на нашей стороне не должен приближать пользователя к блокировке. Ошибка только логируется.
+func (a *Auth) releaseLoginAttempt(ctx context.Context, email, ip string, attempt *dto.LoginAttempt) {
+	if err := a.attempts.ReleaseLoginAttempt(ctx, email, ip, attempt.ID); err != nil {
+		slog.Error("Не удалось снять попытку входа", "email", email, "error", err)
+	}
+}
+
+// verifyPasswordLimited - проверяет пароль уже вошедшего пользователя с теми же счётчиками и блокировкой, что и вход:
+// иначе с чужой сессией пароль можно было бы подбирать без ограничений. Верный пароль счётчик не сбрасывает -
+// это делает только завершённый вход.
+func (a *Auth) verifyPasswordLimited(ctx context.Context, user *dto.User, password, ip string) error {
+	lockoutEmail := normalizeEmail(user.Email)
+	attempt, err := a.reserveLoginAttempt(ctx, lockoutEmail, ip)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.verifyPassword(ctx, user.ID, password); err != nil {
+		if !errors.Is(err, errors2.PasswordWrong) {
+			a.releaseLoginAttempt(ctx, lockoutEmail, ip, attempt)
+			return err
+		}
+		if lockErr := a.registerLoginFailure(ctx, lockoutEmail, ip, attempt); lockErr != nil {
+			return lockErr
+		}
+		return err
+	}
+
+	a.releaseLoginAttempt(ctx, lockoutEmail, ip, attempt)
+	return nil
+}
+
+// UnlockAccount - досрочно снимает блокировку входа с аккаунта и сбрасывает счётчик его неудачных попыток.
+func (a *Auth) UnlockAccount(ctx context.Context, email string) error {
+	if _, err := a.repo.GetIDByEmail(ctx, email); err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return errors2.UserNotExist
+		}
+		return err
+	}
+
+	if err := a.attempts.UnlockAccount(ctx, normalizeEmail(email)); err != nil {
+		return err
+	}
+
+	slog.Info("Блокировка входа снята", "email", email)
+	return nil
+}
+
+// loginDelay - прогрессивная задержка после failures неудачных попыток: начиная с DelayAfter-й
+// она удваивается с каждой попыткой, но не превышает MaxDelay. DelayAfter = 0 выключает задержку.
+func loginDelay(failures int) time.Duration {
+	cfg := config.LockoutConfig()
+	if cfg.DelayAfter == 0 || failures < cfg.DelayAfter {
+		return 0
+	}
+
+	delay := cfg.BaseDelay
+	for i := cfg.DelayAfter; i < failures && delay < cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, cfg.MaxDelay)
+}
+
+// normalizeEmail - приводит email к виду, в котором по нему ведутся счётчики попыток входа.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"DBManager/internal/repository"
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/dto"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	defaultItemsLimit = 50
+	maxItemsLimit     = 500
+)
+
+type IManager interface {
+	CreateItem(ctx context.Context, req *dto.CreateItemRequest) (*dto.Item, error)
+	GetItem(ctx context.Context, itemID int) (*dto.Item, error)
+	ListItems(ctx context.Context, filter *dto.ItemFilter) ([]dto.Item, error)
+	UpdateItem(ctx context.Context, req *dto.UpdateItemRequest) (*dto.Item, error)
+	DeleteItem(ctx context.Context, itemID int) error
+
+	CreateWarehouse(ctx context.Context, req *dto.CreateWarehouseRequest) (*dto.Warehouse, error)
+	ListWarehouses(ctx context.Context) ([]dto.Warehouse, error)
+	CreateLocation(ctx context.Context, req *dto.CreateLocationRequest) (*dto.Location, error)
+	ListLocations(ctx context.Context, warehouseID int) ([]dto.Location, error)
+	GetItemStock(ctx context.Context, itemID int) (*dto.ItemStock, error)
+	GetItemStockInWarehouse(ctx context.Context, itemID, warehouseID int) (*dto.ItemStock, error)
+
+	RecordMovement(ctx context.Context, claims *dto.AccessToken, req *dto.MovementRequest) (*dto.StockMovement, error)
+	ListMovements(ctx context.Context, filter *dto.MovementFilter) ([]dto.StockMovement, error)
+	ReconcileStock(ctx context.Context, itemID int) ([]dto.StockDiscrepancy, error)
+	ApplyStockReconciliation(ctx context.Context, userID, itemID int) ([]dto.StockDiscrepancy, error)
+}
+
+type Manager struct {
+	repo IManagerRepository
+}
+
+func NewManager(repo IManagerRepository) *Manager {
+	return &Manager{repo: repo}
+}
+
+// CreateItem - добавляет новый товар в каталог.
+func (m *Manager) CreateItem(ctx context.Context, req *dto.CreateItemRequest) (*dto.Item, error) {
+	item := dto.Item{
+		SKU:         strings.TrimSpace(req.SKU),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Unit:        strings.TrimSpace(req.Unit),
+		Category:    strings.TrimSpace(req.Category),
+		Attributes:  req.Attributes,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := validateItem(&item); err != nil {
+		return nil, err
+	}
+
+	if err := m.repo.CreateItem(ctx, &item); err != nil {
+		if errors.Is(err, repository.RecordAlreadyExist) {
+			return nil, errors2.ErrSKUAlreadyExist
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// GetItem - возвращает товар по id.
+func (m *Manager) GetItem(ctx context.Context, itemID int) (*dto.Item, error) {
+	item, err := m.repo.GetItemByID(ctx, itemID)
+	if err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return nil, errors2.ErrItemNotFound
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// ListItems - возвращает страницу каталога, ограничивая размер выборки.
+func (m *Manager) ListItems(ctx context.Context, filter *dto.ItemFilter) ([]dto.Item, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultItemsLimit
+	}
+	if filter.Limit > maxItemsLimit {
+		filter.Limit = maxItemsLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return m.repo.ListItems(ctx, filter)
+}
+
+// UpdateItem - изменяет переданные поля товара.
+func (m *Manager) UpdateItem(ctx context.Context, req *dto.UpdateItemRequest) (*dto.Item, error) {
+	item, err := m.GetItem(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SKU != nil {
+		item.SKU = strings.TrimSpace(*req.SKU)
+	}
+	if req.Name != nil {
+		item.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		item.Description = *req.Description
+	}
+	if req.Unit != nil {
+		item.Unit = strings.TrimSpace(*req.Unit)
+	}
+	if req.Category != nil {
+		item.Category = strings.TrimSpace(*req.Category)
+	}
+	if req.Attributes != nil {
+		item.Attributes = *req.Attributes
+	}
+	item.UpdatedAt = time.Now()
+
+	if err := validateItem(item); err != nil {
+		return nil, err
+	}
+
+	if err := m.repo.UpdateItem(ctx, item); err != nil {
+		if errors.Is(err, repository.RecordAlreadyExist) {
+			return nil, errors2.ErrSKUAlreadyExist
+		}
+		if errors.Is(err, repository.RecordNotFound) {
+			return nil, errors2.ErrItemNotFound
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// DeleteItem - удаляет товар из каталога. Товар с остатками или историей движений не удаляется - ErrItemInUse.
+func (m *Manager) DeleteItem(ctx context.Context, itemID int) error {
+	if err := m.repo.DeleteItem(ctx, itemID); err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return errors2.ErrItemNotFound
+		}
+		if errors.Is(err, repository.RecordInUse) {
+			return errors2.ErrItemInUse
+		}
+		return err
+	}
+
+	return nil
+}
+
+// validateItem - проверяет обязательные поля товара.
+func validateItem(item *dto.Item) error {
+	if item.SKU == "" || item.Name == "" || item.Unit == "" {
+		return errors2.ErrInvalidItemFormat
+	}
+	return nil
+}
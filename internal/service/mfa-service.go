@@ -0,0 +1,304 @@
+package service
+
+import (
+	"DBManager/internal/repository"
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/totp"
+	"DBManager/internal/shared/utils"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// recoveryCodesCount - сколько кодов восстановления выдаётся при подключении второй ступени.
+const recoveryCodesCount = 10
+
+// EnrollTOTP - начинает подключение второй ступени: создаёт секрет, который пользователь добавляет
+// в приложение-аутентификатор. Вторая ступень включится после подтверждения кодом в ConfirmTOTP.
+func (a *Auth) EnrollTOTP(ctx context.Context, claims *dto.AccessToken) (*dto.TOTPEnrollment, error) {
+	enabled, err := a.mfaEnabled(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, errors2.ErrMFAAlreadyEnabled
+	}
+
+	user, err := a.repo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.SaveTOTPSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &dto.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(config.AuthConfig().MFAIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP - включает вторую ступень, если код из приложения верный, и выдаёт коды восстановления.
+// Коды показываются один раз - в БД хранятся только их хэши.
+func (a *Auth) ConfirmTOTP(ctx context.Context, claims *dto.AccessToken, code string) ([]string, error) {
+	secret, err := a.repo.GetTOTP(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return nil, errors2.ErrMFANotEnabled
+		}
+		return nil, err
+	}
+	if secret.ConfirmedAt != nil {
+		return nil, errors2.ErrMFAAlreadyEnabled
+	}
+
+	var codes []string
+	err = a.repo.WithTx(ctx, func(ctx context.Context) error {
+		if err := a.useTOTPCode(ctx, secret, code); err != nil {
+			return err
+		}
+		if err := a.repo.ConfirmTOTP(ctx, claims.UserID); err != nil {
+			return err
+		}
+
+		codes, err = a.replaceRecoveryCodes(ctx, claims.UserID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Двухфакторная аутентификация подключена", "user_id", claims.UserID)
+	return codes, nil
+}
+
+// DisableTOTP - отключает вторую ступень. Требует текущий пароль и код из приложения,
+// неверный пароль учитывается в блокировке аккаунта, как при входе.
+func (a *Auth) DisableTOTP(ctx context.Context, claims *dto.AccessToken, req *dto.DisableTOTPRequest, ipAddress string) error {
+	user, err := a.repo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	if err := a.verifyPasswordLimited(ctx, user, req.Password, ipAddress); err != nil {
+		return err
+	}
+
+	secret, err := a.confirmedTOTP(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	if err := a.useTOTPCode(ctx, secret, req.Code); err != nil {
+		return err
+	}
+
+	if err := a.repo.DeleteTOTP(ctx, claims.UserID); err != nil {
+		return err
+	}
+
+	slog.Info("Двухфакторная аутентификация отключена", "user_id", claims.UserID)
+	return nil
+}
+
+// RegenerateRecoveryCodes - заменяет коды восстановления новыми, прежние перестают действовать.
+func (a *Auth) RegenerateRecoveryCodes(ctx context.Context, claims *dto.AccessToken, code string) ([]string, error) {
+	secret, err := a.confirmedTOTP(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.useTOTPCode(ctx, secret, code); err != nil {
+		return nil, err
+	}
+
+	return a.replaceRecoveryCodes(ctx, claims.UserID)
+}
+
+// VerifyMFA - второй шаг входа: обменивает вызов и код из приложения (или код восстановления) на пару токенов.
+// Неверные коды учитываются вместе с неверными паролями и ведут к блокировке аккаунта.
+func (a *Auth) VerifyMFA(ctx context.Context, req *dto.VerifyMFARequest, deviceInfo, ipAddress string) (*dto.TokenPair, error) {
+	challengeHash := utils.HashToken(req.ChallengeToken)
+
+	challenge, err := a.repo.GetOneTimeToken(ctx, dto.TokenPurposeMFAChallenge, challengeHash)
+	if err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return nil, errors2.ErrMFAChallengeInvalid
+		}
+		return nil, err
+	}
+
+	user, err := a.repo.GetUserByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	lockoutEmail := normalizeEmail(user.Email)
+	attempt, err := a.reserveLoginAttempt(ctx, lockoutEmail, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.verifySecondFactor(ctx, user.ID, req); err != nil {
+		if errors.Is(err, errors2.ErrMFACodeInvalid) {
+			if err := a.registerLoginFailure(ctx, lockoutEmail, ipAddress, attempt); err != nil {
+				return nil, err
+			}
+		} else {
+			a.releaseLoginAttempt(ctx, lockoutEmail, ipAddress, attempt)
+		}
+		return nil, err
+	}
+
+	// Гасим вызов: параллельный запрос с тем же вызовом токенов не получит.
+	if _, err := a.repo.ConsumeOneTimeToken(ctx, dto.TokenPurposeMFAChallenge, challengeHash); err != nil {
+		a.releaseLoginAttempt(ctx, lockoutEmail, ipAddress, attempt)
+		if errors.Is(err, repository.RecordNotFound) {
+			return nil, errors2.ErrMFAChallengeInvalid
+		}
+		return nil, err
+	}
+
+	if err := a.attempts.ResetLoginFailures(ctx, lockoutEmail, ipAddress, attempt.ID); err != nil {
+		return nil, err
+	}
+
+	return a.startSession(ctx, user, deviceInfo, ipAddress, true)
+}
+
+// createMFAChallenge - выпускает вызов второй ступени для пользователя, прошедшего проверку пароля.
+func (a *Auth) createMFAChallenge(ctx context.Context, userID int) (*dto.MFAChallenge, error) {
+	// Действует только последний вызов - предыдущие гасим.
+	if err := a.repo.InvalidateOneTimeTokens(ctx, userID, dto.TokenPurposeMFAChallenge); err != nil {
+		return nil, err
+	}
+
+	token, tokenHash := utils.GenerateOpaqueToken()
+	ttl := config.AuthConfig().MFAChallengeTTL
+
+	if err := a.repo.CreateOneTimeToken(ctx, &dto.OneTimeToken{
+		UserID:    userID,
+		Purpose:   dto.TokenPurposeMFAChallenge,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &dto.MFAChallenge{
+		MFARequired:    true,
+		ChallengeToken: token,
+		ExpiresIn:      int(ttl.Seconds()),
+	}, nil
+}
+
+// verifySecondFactor - проверяет код из приложения, а если передан код восстановления - гасит его.
+func (a *Auth) verifySecondFactor(ctx context.Context, userID int, req *dto.VerifyMFARequest) error {
+	if req.RecoveryCode != "" {
+		err := a.repo.ConsumeRecoveryCode(ctx, userID, hashRecoveryCode(req.RecoveryCode))
+		if err != nil {
+			if errors.Is(err, repository.RecordNotFound) {
+				return errors2.ErrMFACodeInvalid
+			}
+			return err
+		}
+
+		slog.Warn("Вход по коду восстановления", "user_id", userID)
+		return nil
+	}
+
+	secret, err := a.confirmedTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return a.useTOTPCode(ctx, secret, req.Code)
+}
+
+// useTOTPCode - проверяет код и запоминает его интервал, чтобы тот же код нельзя было использовать повторно.
+func (a *Auth) useTOTPCode(ctx context.Context, secret *dto.UserTOTP, code string) error {
+	step, ok := totp.Validate(secret.Secret, code, time.Now())
+	if !ok {
+		return errors2.ErrMFACodeInvalid
+	}
+
+	if err := a.repo.UseTOTPStep(ctx, secret.UserID, step); err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return errors2.ErrMFACodeInvalid
+		}
+		return err
+	}
+
+	return nil
+}
+
+// mfaEnabled - подключена ли у пользователя вторая ступень.
+func (a *Auth) mfaEnabled(ctx context.Context, userID int) (bool, error) {
+	_, err := a.confirmedTOTP(ctx, userID)
+	if errors.Is(err, errors2.ErrMFANotEnabled) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// confirmedTOTP - возвращает секрет пользователя, если вторая ступень подключена, иначе ErrMFANotEnabled.
+func (a *Auth) confirmedTOTP(ctx context.Context, userID int) (*dto.UserTOTP, error) {
+	secret, err := a.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return nil, errors2.ErrMFANotEnabled
+		}
+		return nil, err
+	}
+	if secret.ConfirmedAt == nil {
+		return nil, errors2.ErrMFANotEnabled
+	}
+
+	return secret, nil
+}
+
+// replaceRecoveryCodes - выпускает новый набор кодов восстановления и возвращает их в открытом виде.
+func (a *Auth) replaceRecoveryCodes(ctx context.Context, userID int) ([]string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := a.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode - случайный код вида abcde-fghij (50 бит).
+func generateRecoveryCode() (string, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(raw))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode - хэш кода восстановления без учёта регистра, пробелов и дефисов.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return utils.HashToken(code)
+}
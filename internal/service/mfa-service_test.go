@@ -0,0 +1,312 @@
+package service
+
+import (
+	"DBManager/internal/repository"
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/events"
+	"DBManager/internal/shared/jwtkeys"
+	"DBManager/internal/shared/passwords"
+	"DBManager/internal/shared/totp"
+	"context"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const (
+	testEmail    = "user@example.com"
+	testPassword = "correct horse battery staple"
+	testIP       = "203.0.113.7"
+)
+
+// fakeMFARepo - пользователи из fakeAuthRepo со второй ступенью, одноразовыми токенами и кодами восстановления в памяти.
+type fakeMFARepo struct {
+	*fakeAuthRepo
+
+	totp          map[int]*dto.UserTOTP
+	tokens        map[string]*dto.OneTimeToken
+	recoveryCodes map[int]map[string]bool // Хэши неиспользованных кодов восстановления
+}
+
+func newFakeMFARepo(user dto.User, secret string) *fakeMFARepo {
+	confirmed := time.Now()
+	return &fakeMFARepo{
+		fakeAuthRepo:  newFakeAuthRepo(user),
+		totp:          map[int]*dto.UserTOTP{user.ID: {UserID: user.ID, Secret: secret, ConfirmedAt: &confirmed}},
+		tokens:        map[string]*dto.OneTimeToken{},
+		recoveryCodes: map[int]map[string]bool{user.ID: {}},
+	}
+}
+
+func (r *fakeMFARepo) GetHashByID(_ context.Context, userID int) (string, error) {
+	u, ok := r.users[userID]
+	if !ok {
+		return "", repository.RecordNotFound
+	}
+	return u.Hash, nil
+}
+
+func (r *fakeMFARepo) GetTOTP(_ context.Context, userID int) (*dto.UserTOTP, error) {
+	secret, ok := r.totp[userID]
+	if !ok {
+		return nil, repository.RecordNotFound
+	}
+	s := *secret
+	return &s, nil
+}
+
+func (r *fakeMFARepo) DeleteTOTP(_ context.Context, userID int) error {
+	delete(r.totp, userID)
+	return nil
+}
+
+func (r *fakeMFARepo) UseTOTPStep(_ context.Context, userID int, step int64) error {
+	secret, ok := r.totp[userID]
+	if !ok || step <= secret.LastStep {
+		return repository.RecordNotFound
+	}
+	secret.LastStep = step
+	return nil
+}
+
+func (r *fakeMFARepo) ConsumeRecoveryCode(_ context.Context, userID int, hash string) error {
+	if !r.recoveryCodes[userID][hash] {
+		return repository.RecordNotFound
+	}
+	delete(r.recoveryCodes[userID], hash)
+	return nil
+}
+
+func (r *fakeMFARepo) ReplaceRecoveryCodes(_ context.Context, userID int, hashes []string) error {
+	r.recoveryCodes[userID] = map[string]bool{}
+	for _, hash := range hashes {
+		r.recoveryCodes[userID][hash] = true
+	}
+	return nil
+}
+
+func (r *fakeMFARepo) CreateOneTimeToken(_ context.Context, token *dto.OneTimeToken) error {
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *fakeMFARepo) GetOneTimeToken(_ context.Context, purpose, hash string) (*dto.OneTimeToken, error) {
+	token, ok := r.tokens[hash]
+	if !ok || token.Purpose != purpose || token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, repository.RecordNotFound
+	}
+	return token, nil
+}
+
+func (r *fakeMFARepo) ConsumeOneTimeToken(ctx context.Context, purpose, hash string) (int, error) {
+	token, err := r.GetOneTimeToken(ctx, purpose, hash)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return token.UserID, nil
+}
+
+func (r *fakeMFARepo) InvalidateOneTimeTokens(_ context.Context, userID int, purpose string) error {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+// fakeLoginAttempts - счётчики попыток входа как в Redis, но без скользящего окна и задержек:
+// время в тестах не идёт, все попытки укладываются в окно.
+type fakeLoginAttempts struct {
+	byEmail map[string]map[string]bool // Попытки аккаунта
+	locked  map[string]bool
+	nextID  int
+}
+
+func newFakeLoginAttempts() *fakeLoginAttempts {
+	return &fakeLoginAttempts{byEmail: map[string]map[string]bool{}, locked: map[string]bool{}}
+}
+
+func (f *fakeLoginAttempts) ReserveLoginAttempt(_ context.Context, email, _ string, limits *dto.LoginAttemptLimits) (*dto.LoginAttempt, error) {
+	if f.locked[email] {
+		return &dto.LoginAttempt{Denied: dto.LoginDeniedLocked, RetryAfter: limits.LockoutFor}, nil
+	}
+	if len(f.byEmail[email]) >= limits.MaxFailures {
+		return &dto.LoginAttempt{Denied: dto.LoginDeniedLocked, RetryAfter: limits.LockoutFor, ByEmail: len(f.byEmail[email])}, nil
+	}
+
+	f.nextID++
+	id := strconv.Itoa(f.nextID)
+	if f.byEmail[email] == nil {
+		f.byEmail[email] = map[string]bool{}
+	}
+	f.byEmail[email][id] = true
+	return &dto.LoginAttempt{ID: id, ByEmail: len(f.byEmail[email])}, nil
+}
+
+func (f *fakeLoginAttempts) ReleaseLoginAttempt(_ context.Context, email, _, attemptID string) error {
+	delete(f.byEmail[email], attemptID)
+	return nil
+}
+
+func (f *fakeLoginAttempts) ResetLoginFailures(_ context.Context, email, _, _ string) error {
+	delete(f.byEmail, email)
+	return nil
+}
+
+func (f *fakeLoginAttempts) LockAccount(_ context.Context, email string, _ time.Duration) error {
+	f.locked[email] = true
+	return nil
+}
+
+func (f *fakeLoginAttempts) UnlockAccount(_ context.Context, email string) error {
+	delete(f.locked, email)
+	delete(f.byEmail, email)
+	return nil
+}
+
+// newMFATestAuth - сервис с пользователем 1, у которого подключена вторая ступень.
+func newMFATestAuth(t *testing.T) (*Auth, *fakeMFARepo, *fakeLoginAttempts) {
+	t.Helper()
+
+	hasher := passwords.NewBcrypt(bcrypt.MinCost)
+	hash, err := hasher.Hash(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := jwtkeys.NewHMAC("test-access-secret-test-access-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := newFakeMFARepo(dto.User{ID: 1, Email: testEmail, EmailVerified: true, Hash: hash}, secret)
+	attempts := newFakeLoginAttempts()
+	a := &Auth{
+		repo:     repo,
+		jwtRepo:  &fakeJWTRepo{blocked: map[string]bool{}},
+		attempts: attempts,
+		events:   events.NewLogEmitter(),
+		keys:     keys,
+		hasher:   hasher,
+	}
+	return a, repo, attempts
+}
+
+// wrongTOTPCode - код давно прошедшего интервала: в окно допуска он не попадает.
+func wrongTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	code, err := totp.Code(secret, totp.Step(time.Now())-100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestMFAFailuresSurviveSignIn(t *testing.T) {
+	a, repo, _ := newMFATestAuth(t)
+	ctx := context.Background()
+	maxFailures := config.LockoutConfig().MaxFailures
+	wrongCode := wrongTOTPCode(t, repo.totp[1].Secret)
+
+	// Знающий пароль чередует вход и неверные коды: верный пароль не должен обнулять счётчик,
+	// поэтому аккаунт блокируется после MaxFailures неверных кодов, как если бы вход был один.
+	failures := 0
+	for failures < maxFailures {
+		_, challenge, err := a.Authentication(ctx, &dto.SignInRequest{Email: testEmail, Password: testPassword}, "", testIP)
+		if err != nil {
+			t.Fatalf("вход после %d неверных кодов: %v", failures, err)
+		}
+		if challenge == nil {
+			t.Fatal("при подключённой второй ступени вход должен выдавать вызов")
+		}
+
+		for i := 0; i < 2 && failures < maxFailures; i++ {
+			_, err := a.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenge.ChallengeToken, Code: wrongCode}, "", testIP)
+			if !errors.Is(err, errors2.ErrMFACodeInvalid) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, errors2.ErrMFACodeInvalid)
+			}
+			failures++
+		}
+	}
+
+	_, _, err := a.Authentication(ctx, &dto.SignInRequest{Email: testEmail, Password: testPassword}, "", testIP)
+	if !errors.Is(err, errors2.ErrAccountLocked) {
+		t.Fatalf("после %d неверных кодов ошибка %v, ожидалась %v", failures, err, errors2.ErrAccountLocked)
+	}
+}
+
+func TestDisableTOTPLockout(t *testing.T) {
+	a, repo, _ := newMFATestAuth(t)
+	ctx := context.Background()
+	claims := &dto.AccessToken{UserID: 1}
+
+	for i := 0; i < config.LockoutConfig().MaxFailures; i++ {
+		err := a.DisableTOTP(ctx, claims, &dto.DisableTOTPRequest{Password: "wrong password"}, testIP)
+		if !errors.Is(err, errors2.PasswordWrong) {
+			t.Fatalf("попытка %d: ошибка %v, ожидалась %v", i+1, err, errors2.PasswordWrong)
+		}
+	}
+
+	// После блокировки не проходит и верный пароль: вторая ступень остаётся подключённой.
+	err := a.DisableTOTP(ctx, claims, &dto.DisableTOTPRequest{Password: testPassword}, testIP)
+	if !errors.Is(err, errors2.ErrAccountLocked) {
+		t.Fatalf("ошибка %v, ожидалась %v", err, errors2.ErrAccountLocked)
+	}
+	if _, ok := repo.totp[1]; !ok {
+		t.Fatal("вторая ступень не должна отключаться")
+	}
+}
+
+func TestRecoveryCodeSingleUse(t *testing.T) {
+	a, repo, _ := newMFATestAuth(t)
+	ctx := context.Background()
+
+	codes, err := a.replaceRecoveryCodes(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodesCount || len(repo.recoveryCodes[1]) != recoveryCodesCount {
+		t.Fatalf("выпущено %d кодов, сохранено %d, ожидалось %d", len(codes), len(repo.recoveryCodes[1]), recoveryCodesCount)
+	}
+
+	signIn := func(recoveryCode string) error {
+		_, challenge, err := a.Authentication(ctx, &dto.SignInRequest{Email: testEmail, Password: testPassword}, "", testIP)
+		if err != nil {
+			t.Fatalf("вход: %v", err)
+		}
+		_, err = a.VerifyMFA(ctx, &dto.VerifyMFARequest{ChallengeToken: challenge.ChallengeToken, RecoveryCode: recoveryCode}, "", testIP)
+		return err
+	}
+
+	// Код восстановления гасится при первом входе, повторно он не принимается, а остальные коды действуют.
+	if err := signIn(codes[0]); err != nil {
+		t.Fatalf("первый вход по коду: %v", err)
+	}
+	if err := signIn(codes[0]); !errors.Is(err, errors2.ErrMFACodeInvalid) {
+		t.Fatalf("повторный вход по коду: ошибка %v, ожидалась %v", err, errors2.ErrMFACodeInvalid)
+	}
+	if err := signIn(codes[1]); err != nil {
+		t.Fatalf("вход по другому коду: %v", err)
+	}
+
+	// Новый набор заменяет прежний целиком.
+	if _, err := a.replaceRecoveryCodes(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := signIn(codes[2]); !errors.Is(err, errors2.ErrMFACodeInvalid) {
+		t.Fatalf("вход по коду из прежнего набора: ошибка %v, ожидалась %v", err, errors2.ErrMFACodeInvalid)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"DBManager/internal/repository"
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/oidc"
+	"DBManager/internal/shared/rbac"
+	"DBManager/internal/shared/utils"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// OIDCLoginURL - начинает вход через внешнего провайдера: возвращает адрес его страницы входа и state,
+// который контроллер привязывает к браузеру пользователя.
+func (a *Auth) OIDCLoginURL(ctx context.Context) (string, string, error) {
+	if a.oidc == nil {
+		return "", "", errors2.ErrOIDCDisabled
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	loginURL, err := a.oidc.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	// nonce и verifier остаются у нас до возврата пользователя, провайдер видит только nonce и хэш verifier.
+	if err := a.repo.SaveOIDCState(ctx, utils.HashToken(state), &dto.OIDCState{
+		Nonce:    nonce,
+		Verifier: verifier,
+	}, config.OIDCConfig().StateTTL); err != nil {
+		return "", "", err
+	}
+
+	return loginURL, state, nil
+}
+
+// OIDCCallback - завершает вход через внешнего провайдера: обменивает код на ID токен, находит или создаёт
+// пользователя и начинает сессию так же, как вход по паролю, включая вызов второй ступени.
+func (a *Auth) OIDCCallback(ctx context.Context, code, state, deviceInfo, ipAddress string) (*dto.TokenPair, *dto.MFAChallenge, error) {
+	if a.oidc == nil {
+		return nil, nil, errors2.ErrOIDCDisabled
+	}
+
+	saved, err := a.repo.ConsumeOIDCState(ctx, utils.HashToken(state))
+	if err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return nil, nil, errors2.ErrOIDCStateInvalid
+		}
+		return nil, nil, err
+	}
+
+	rawIDToken, err := a.oidc.Exchange(ctx, code, saved.Verifier)
+	if err != nil {
+		slog.Warn("Провайдер OIDC не выдал ID токен", "ip", ipAddress, "error", err)
+		return nil, nil, errors2.ErrOIDCLoginFailed
+	}
+
+	claims, err := a.oidc.VerifyIDToken(ctx, rawIDToken, saved.Nonce)
+	if err != nil {
+		slog.Warn("ID токен провайдера OIDC отклонён", "ip", ipAddress, "error", err)
+		return nil, nil, errors2.ErrOIDCLoginFailed
+	}
+
+	user, err := a.oidcUser(ctx, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Вторая ступень требуется и при входе через провайдера: он подтверждает только первую.
+	mfaEnabled, err := a.mfaEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mfaEnabled {
+		challenge, err := a.createMFAChallenge(ctx, user.ID)
+		return nil, challenge, err
+	}
+
+	tokens, err := a.startSession(ctx, user, deviceInfo, ipAddress, false)
+	return tokens, nil, err
+}
+
+// oidcUser - находит пользователя по внешнему аккаунту. При первом входе аккаунт привязывается
+// к пользователю с тем же email, а если такого нет и разрешено автосоздание - к новому пользователю.
+func (a *Auth) oidcUser(ctx context.Context, claims *oidc.Claims) (*dto.User, error) {
+	issuer := a.oidc.Issuer()
+
+	identity, err := a.repo.GetUserIdentity(ctx, issuer, claims.Subject)
+	if err == nil {
+		if err := a.repo.TouchUserIdentity(ctx, identity.ID, claims.Email); err != nil {
+			return nil, err
+		}
+		return a.repo.GetUserByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, repository.RecordNotFound) {
+		return nil, err
+	}
+
+	// Привязываем по email только подтверждённый провайдером адрес, иначе аккаунт провайдера
+	// с чужим неподтверждённым email получил бы доступ к пользователю.
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, errors2.ErrOIDCEmailNotVerified
+	}
+
+	var user *dto.User
+	err = a.repo.WithTx(ctx, func(ctx context.Context) error {
+		userID, err := a.repo.GetIDByEmail(ctx, claims.Email)
+		switch {
+		case err == nil:
+			user, err = a.linkExistingUser(ctx, userID)
+		case errors.Is(err, repository.RecordNotFound):
+			user, err = a.provisionUser(ctx, claims)
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return a.repo.CreateUserIdentity(ctx, &dto.UserIdentity{
+			UserID:      user.ID,
+			Issuer:      issuer,
+			Subject:     claims.Subject,
+			Email:       claims.Email,
+			LastLoginAt: &now,
+			CreatedAt:   now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Внешний аккаунт привязан к пользователю", "user_id", user.ID, "issuer", issuer)
+	return user, nil
+}
+
+// linkExistingUser - готовит к привязке пользователя, найденного по email.
+// Если пользователь не подтвердил email, аккаунт мог заранее зарегистрировать кто-то другой, знающий пароль.
+// Провайдер подтвердил, что адрес принадлежит входящему, поэтому пароль сбрасывается, а сессии завершаются.
+func (a *Auth) linkExistingUser(ctx context.Context, userID int) (*dto.User, error) {
+	user, err := a.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.EmailVerified {
+		return user, nil
+	}
+
+	hash, err := a.unusablePasswordHash()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.ChangeHashDB(ctx, user.ID, hash); err != nil {
+		return nil, err
+	}
+	if err := a.repo.SetEmailVerified(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	if _, err := a.revokeSessions(ctx, user.ID, ""); err != nil {
+		return nil, err
+	}
+
+	slog.Warn("Пароль пользователя с неподтверждённым email сброшен при привязке внешнего аккаунта", "user_id", user.ID)
+	user.EmailVerified = true
+	return user, nil
+}
+
+// provisionUser - создаёт пользователя по данным провайдера. Пароля у него нет:
+// при необходимости его можно задать через восстановление пароля.
+func (a *Auth) provisionUser(ctx context.Context, claims *oidc.Claims) (*dto.User, error) {
+	if !config.OIDCConfig().AutoProvision {
+		return nil, errors2.ErrOIDCUserNotFound
+	}
+
+	hash, err := a.unusablePasswordHash()
+	if err != nil {
+		return nil, err
+	}
+
+	user := &dto.User{
+		FirstName:     claims.GivenName,
+		LastName:      claims.FamilyName,
+		Email:         claims.Email,
+		EmailVerified: true,
+		Hash:          hash,
+		Role:          string(rbac.DefaultRole),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := a.repo.AddUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Пользователь создан при входе через внешнего провайдера", "user_id", user.ID)
+	return user, nil
+}
+
+// unusablePasswordHash - хэш случайного пароля, который никто не знает: войти по паролю нельзя.
+func (a *Auth) unusablePasswordHash() (string, error) {
+	password, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	return a.hasher.Hash(password)
+}
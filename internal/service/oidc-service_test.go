@@ -0,0 +1,342 @@
+package service
+
+import (
+	"DBManager/internal/repository"
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/dto"
+	cfgdto "DBManager/internal/shared/dto/config"
+	"DBManager/internal/shared/oidc"
+	"DBManager/internal/shared/passwords"
+	"context"
+	"errors"
+	"flag"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// Сервис читает настройки из загруженной конфигурации, подключения к БД в тестах не открываются.
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if _, err := config.Load(fs, []string{
+		"-postgres-connect-string", "postgres://test",
+		"-redis-addr", "localhost:6379",
+		"-redis-pass", "test",
+		"-access-secret", "test-access-secret-test-access-secret",
+		"-mailer-driver", cfgdto.MailerDriverMemory,
+	}); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+// fakeAuthRepo - пользователи, внешние аккаунты и state входа в памяти. Остальные методы IAuthRepository
+// в этих тестах не вызываются: обращение к ним - паника на nil интерфейсе.
+type fakeAuthRepo struct {
+	IAuthRepository
+
+	users      map[int]*dto.User
+	identities []dto.UserIdentity
+	states     map[string]*dto.OIDCState
+}
+
+func newFakeAuthRepo(users ...dto.User) *fakeAuthRepo {
+	r := &fakeAuthRepo{users: map[int]*dto.User{}, states: map[string]*dto.OIDCState{}}
+	for _, u := range users {
+		u := u
+		r.users[u.ID] = &u
+	}
+	return r
+}
+
+func (r *fakeAuthRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *fakeAuthRepo) GetIDByEmail(_ context.Context, email string) (int, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			return u.ID, nil
+		}
+	}
+	return 0, repository.RecordNotFound
+}
+
+func (r *fakeAuthRepo) GetUserByID(_ context.Context, userID int) (*dto.User, error) {
+	u, ok := r.users[userID]
+	if !ok {
+		return nil, repository.RecordNotFound
+	}
+	user := *u
+	return &user, nil
+}
+
+func (r *fakeAuthRepo) AddUser(_ context.Context, user *dto.User) error {
+	user.ID = len(r.users) + 1
+	u := *user
+	r.users[u.ID] = &u
+	return nil
+}
+
+func (r *fakeAuthRepo) ChangeHashDB(_ context.Context, userID int, hash string) error {
+	r.users[userID].Hash = hash
+	return nil
+}
+
+func (r *fakeAuthRepo) SetEmailVerified(_ context.Context, userID int) error {
+	r.users[userID].EmailVerified = true
+	return nil
+}
+
+func (r *fakeAuthRepo) GetUserIdentity(_ context.Context, issuer, subject string) (*dto.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, repository.RecordNotFound
+}
+
+func (r *fakeAuthRepo) CreateUserIdentity(_ context.Context, identity *dto.UserIdentity) error {
+	identity.ID = len(r.identities) + 1
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *fakeAuthRepo) TouchUserIdentity(context.Context, int, string) error {
+	return nil
+}
+
+func (r *fakeAuthRepo) SaveOIDCState(_ context.Context, stateHash string, state *dto.OIDCState, _ time.Duration) error {
+	r.states[stateHash] = state
+	return nil
+}
+
+func (r *fakeAuthRepo) ConsumeOIDCState(_ context.Context, stateHash string) (*dto.OIDCState, error) {
+	state, ok := r.states[stateHash]
+	if !ok {
+		return nil, repository.RecordNotFound
+	}
+	delete(r.states, stateHash)
+	return state, nil
+}
+
+// fakeJWTRepo - Refresh токены пользователей и чёрный список Access токенов в памяти.
+type fakeJWTRepo struct {
+	IJWTTokenRepository
+
+	tokens  []dto.RefreshToken
+	blocked map[string]bool
+}
+
+func (r *fakeJWTRepo) CreateRefreshToken(_ context.Context, token *dto.RefreshToken) error {
+	token.ID = len(r.tokens) + 1
+	r.tokens = append(r.tokens, *token)
+	return nil
+}
+
+func (r *fakeJWTRepo) ListActiveRefreshTokens(_ context.Context, userID int) ([]dto.RefreshToken, error) {
+	var active []dto.RefreshToken
+	for _, t := range r.tokens {
+		if t.UserID == userID && !t.IsRevoked {
+			active = append(active, t)
+		}
+	}
+	return active, nil
+}
+
+func (r *fakeJWTRepo) RevokeRefreshTokenFamily(_ context.Context, userID int, familyID string) (int, error) {
+	revoked := 0
+	for i := range r.tokens {
+		if t := &r.tokens[i]; t.UserID == userID && t.FamilyID == familyID && !t.IsRevoked {
+			t.IsRevoked = true
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (r *fakeJWTRepo) ListRefreshTokenFamily(_ context.Context, userID int, familyID string) ([]dto.RefreshToken, error) {
+	var family []dto.RefreshToken
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.FamilyID == familyID {
+			family = append(family, t)
+		}
+	}
+	return family, nil
+}
+
+func (r *fakeJWTRepo) AddAccessToBlackList(_ context.Context, jti string, _ time.Duration) error {
+	r.blocked[jti] = true
+	return nil
+}
+
+// newOIDCTestAuth - сервис с заглушкой провайдера на httptest сервере.
+func newOIDCTestAuth(t *testing.T, repo *fakeAuthRepo, jwtRepo *fakeJWTRepo) *Auth {
+	t.Helper()
+
+	var stub *oidc.Stub
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.Handler().ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	stub, err := oidc.NewStub(srv.URL, "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := oidc.New(&cfgdto.OIDCConfig{
+		Issuer:      srv.URL,
+		ClientID:    "inventory",
+		RedirectURL: "https://app.example.com/OIDCCallback",
+		Scopes:      []string{"openid", "email"},
+		HTTPTimeout: 5 * time.Second,
+	})
+
+	return &Auth{repo: repo, jwtRepo: jwtRepo, oidc: provider, hasher: passwords.NewBcrypt(bcrypt.MinCost)}
+}
+
+func TestOIDCCallbackState(t *testing.T) {
+	repo := newFakeAuthRepo()
+	a := newOIDCTestAuth(t, repo, &fakeJWTRepo{})
+	ctx := context.Background()
+
+	loginURL, state, err := a.OIDCLoginURL(ctx)
+	if err != nil {
+		t.Fatalf("OIDCLoginURL: %v", err)
+	}
+	if got := mustQuery(t, loginURL).Get("state"); got != state {
+		t.Fatalf("провайдеру передан state %q, браузеру - %q", got, state)
+	}
+
+	// state, которого мы не выдавали (подделка или чужой браузер), отклоняется до обращения к провайдеру.
+	if _, _, err := a.OIDCCallback(ctx, "code", "forged-state", "", ""); !errors.Is(err, errors2.ErrOIDCStateInvalid) {
+		t.Fatalf("ошибка %v, ожидалась %v", err, errors2.ErrOIDCStateInvalid)
+	}
+	if len(repo.states) != 1 {
+		t.Fatal("state начатого входа не должен тратиться на чужой запрос")
+	}
+
+	// state одноразовый: после первой попытки повторить её с тем же state нельзя.
+	if _, _, err := a.OIDCCallback(ctx, "unknown-code", state, "", ""); !errors.Is(err, errors2.ErrOIDCLoginFailed) {
+		t.Fatalf("ошибка %v, ожидалась %v", err, errors2.ErrOIDCLoginFailed)
+	}
+	if _, _, err := a.OIDCCallback(ctx, "unknown-code", state, "", ""); !errors.Is(err, errors2.ErrOIDCStateInvalid) {
+		t.Fatalf("ошибка %v, ожидалась %v", err, errors2.ErrOIDCStateInvalid)
+	}
+}
+
+func TestOIDCUser(t *testing.T) {
+	const issuerSubject = "stub|user@example.com"
+
+	tests := []struct {
+		name          string
+		users         []dto.User
+		identity      bool // Внешний аккаунт уже привязан к пользователю 1
+		emailVerified bool // Провайдер подтвердил email
+		autoProvision bool
+		wantErr       error
+		wantUserID    int
+		wantReset     bool // Пароль пользователя сброшен, сессии завершены
+	}{
+		{
+			name:     "уже привязанный аккаунт",
+			users:    []dto.User{{ID: 1, Email: "old@example.com", EmailVerified: true, Hash: "hash"}},
+			identity: true, wantUserID: 1,
+		},
+		{
+			name:    "неподтверждённый провайдером email не привязывается",
+			users:   []dto.User{{ID: 1, Email: "user@example.com", EmailVerified: true, Hash: "hash"}},
+			wantErr: errors2.ErrOIDCEmailNotVerified,
+		},
+		{
+			name:          "привязка к пользователю с подтверждённым email",
+			users:         []dto.User{{ID: 1, Email: "user@example.com", EmailVerified: true, Hash: "hash"}},
+			emailVerified: true, wantUserID: 1,
+		},
+		{
+			name:          "привязка к пользователю с неподтверждённым email сбрасывает пароль",
+			users:         []dto.User{{ID: 1, Email: "user@example.com", Hash: "hash"}},
+			emailVerified: true, wantUserID: 1, wantReset: true,
+		},
+		{
+			name:          "без пользователя и без автосоздания",
+			emailVerified: true, wantErr: errors2.ErrOIDCUserNotFound,
+		},
+		{
+			name:          "автосоздание пользователя",
+			emailVerified: true, autoProvision: true, wantUserID: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeAuthRepo(tt.users...)
+			jwtRepo := &fakeJWTRepo{
+				tokens: []dto.RefreshToken{{
+					UserID: 1, FamilyID: "family", AccessJti: "access-jti", CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour),
+				}},
+				blocked: map[string]bool{},
+			}
+			a := newOIDCTestAuth(t, repo, jwtRepo)
+			if tt.identity {
+				repo.identities = append(repo.identities, dto.UserIdentity{ID: 1, UserID: 1, Issuer: a.oidc.Issuer(), Subject: issuerSubject})
+			}
+
+			cfg := config.OIDCConfig()
+			cfg.AutoProvision = tt.autoProvision
+			t.Cleanup(func() { cfg.AutoProvision = false })
+
+			claims := &oidc.Claims{Email: "user@example.com"}
+			claims.Subject = issuerSubject
+			if tt.emailVerified {
+				claims.EmailVerified = true
+			}
+
+			user, err := a.oidcUser(context.Background(), claims)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(repo.identities) != 0 {
+					t.Fatal("внешний аккаунт не должен привязываться")
+				}
+				return
+			}
+
+			if user.ID != tt.wantUserID {
+				t.Fatalf("пользователь %d, ожидался %d", user.ID, tt.wantUserID)
+			}
+			if _, err := repo.GetUserIdentity(context.Background(), a.oidc.Issuer(), issuerSubject); err != nil {
+				t.Fatalf("внешний аккаунт не привязан: %v", err)
+			}
+			if !repo.users[user.ID].EmailVerified {
+				t.Fatal("email пользователя должен быть подтверждён")
+			}
+
+			reset := repo.users[user.ID].Hash != "hash" && len(tt.users) > 0
+			if reset != tt.wantReset || jwtRepo.blocked["access-jti"] != tt.wantReset {
+				t.Fatalf("сброс пароля %v, блокировка Access токена %v, ожидалось %v", reset, jwtRepo.blocked["access-jti"], tt.wantReset)
+			}
+		})
+	}
+}
+
+func mustQuery(t *testing.T, raw string) url.Values {
+	t.Helper()
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query()
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"DBManager/internal/repository"
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/mailer"
+	"DBManager/internal/shared/passwords"
+	"DBManager/internal/shared/utils"
+	"DBManager/internal/shared/validation"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+// ForgotPassword - отправляет на email письмо со ссылкой для сброса пароля.
+// Письмо отправляется в фоне (mailer.AsyncMailer), поэтому ответ для существующего и несуществующего email не отличается.
+// Для несуществующего email ошибка не возвращается, чтобы по ответу нельзя было перебирать пользователей.
+func (a *Auth) ForgotPassword(ctx context.Context, email string) error {
+	userID, err := a.repo.GetIDByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			slog.Info("Запрошен сброс пароля для несуществующего email")
+			return nil
+		}
+		return err
+	}
+
+	// Действует только последняя ссылка - предыдущие гасим.
+	if err := a.repo.InvalidateOneTimeTokens(ctx, userID, dto.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	token, tokenHash := utils.GenerateOpaqueToken()
+	ttl := config.TokenConfig().ResetTTL
+
+	if err := a.repo.CreateOneTimeToken(ctx, &dto.OneTimeToken{
+		UserID:    userID,
+		Purpose:   dto.TokenPurposePasswordReset,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.MailerConfig().PublicURL, url.QueryEscape(token))
+
+	return a.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Сброс пароля",
+		Body: fmt.Sprintf("Для сброса пароля перейдите по ссылке: %s\n\nСсылка действует %d минут. "+
+			"Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.", link, int(ttl.Minutes())),
+	})
+}
+
+// ResetPassword - устанавливает новый пароль по одноразовому токену и завершает все сессии пользователя.
+func (a *Auth) ResetPassword(ctx context.Context, token, password string) error {
+	var userID int
+	// Токен гасится в момент проверки, поэтому повторно им воспользоваться нельзя. Если новый пароль
+	// не прошёл политику, погашение откатывается вместе с транзакцией и ссылка остаётся действительной.
+	err := a.repo.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		userID, err = a.repo.ConsumeOneTimeToken(ctx, dto.TokenPurposePasswordReset, utils.HashToken(token))
+		if err != nil {
+			if errors.Is(err, repository.RecordNotFound) {
+				return errors2.ErrResetTokenInvalid
+			}
+			return err
+		}
+
+		user, err := a.repo.GetUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return a.setPassword(ctx, user, password, "password")
+	})
+	if err != nil {
+		return err
+	}
+
+	// Пароль мог быть скомпрометирован - завершаем все сессии пользователя вместе с их Access токенами.
+	countRevoke, err := a.revokeSessions(ctx, userID, "")
+	if err != nil {
+		return err
+	}
+	slog.Info("Пароль сброшен, сессии пользователя отозваны", "user_id", userID, "count", countRevoke)
+
+	return nil
+}
+
+// ChangePassword - меняет пароль авторизованного пользователя после проверки текущего.
+// Текущий Access Token блокируется, все сессии отзываются, а для текущего устройства выпускается новая пара токенов.
+func (a *Auth) ChangePassword(ctx context.Context, claims *dto.AccessToken, req *dto.ChangePasswordRequest, deviceInfo, ipAddress string) (*dto.TokenPair, error) {
+	user, err := a.repo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Проверяем текущий пароль: неверные попытки ведут к блокировке, как при входе.
+	if err := a.verifyPasswordLimited(ctx, user, req.CurrentPassword, ipAddress); err != nil {
+		return nil, err
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		return nil, errors2.ErrPasswordUnchanged
+	}
+
+	if err := a.setPassword(ctx, user, req.NewPassword, "new_password"); err != nil {
+		return nil, err
+	}
+
+	// Блокируем Access Token, которым был сделан запрос.
+	if err := a.jwtRepo.AddAccessToBlackList(ctx, claims.Jti, time.Until(claims.ExpiresAt.Time)); err != nil {
+		return nil, err
+	}
+
+	// Завершаем все сессии, включая текущую, вместе с их Access токенами - текущей выдаётся новая пара ниже.
+	countRevoke, err := a.revokeSessions(ctx, claims.UserID, "")
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Пароль изменён, сессии пользователя отозваны", "user_id", claims.UserID, "count", countRevoke)
+
+	return a.issueTokens(ctx, user, deviceInfo, ipAddress, nil, claims.MFA)
+}
+
+// setPassword - проверяет новый пароль пользователя по политике и истории паролей и сохраняет его хэш,
+// прежний хэш уходит в историю. field - поле запроса с паролем, в нём клиент получит нарушение политики.
+func (a *Auth) setPassword(ctx context.Context, user *dto.User, password, field string) error {
+	if err := a.passwords.Check(password, user); err != nil {
+		return passwordError(field, err)
+	}
+
+	current, err := a.repo.GetHashByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	// Без истории пароль сверяется только с текущим.
+	hashes := []string{current}
+	if a.passwords.HistorySize() > 0 {
+		history, err := a.repo.GetPasswordHistory(ctx, user.ID, a.passwords.HistorySize())
+		if err != nil {
+			return err
+		}
+		hashes = append(hashes, history...)
+	}
+	if err := a.passwords.CheckHistory(password, hashes); err != nil {
+		return passwordError(field, err)
+	}
+
+	hash, err := a.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	if a.passwords.HistorySize() == 0 {
+		return a.repo.ChangeHashDB(ctx, user.ID, hash)
+	}
+
+	return a.repo.WithTx(ctx, func(ctx context.Context) error {
+		if err := a.repo.PushPasswordHistory(ctx, user.ID, current, a.passwords.HistorySize()); err != nil {
+			return err
+		}
+		return a.repo.ChangeHashDB(ctx, user.ID, hash)
+	})
+}
+
+// passwordError - переводит нарушение политики паролей в ошибку поля field запроса, как при проверке DTO.
+func passwordError(field string, err error) error {
+	var violation *passwords.Violation
+	if errors.As(err, &violation) {
+		return validation.Errors{{Field: field, Rule: violation.Rule, Param: violation.Param}}
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/dto"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestChangePasswordLockout(t *testing.T) {
+	a, _, _ := newMFATestAuth(t)
+	ctx := context.Background()
+	claims := &dto.AccessToken{UserID: 1}
+	req := &dto.ChangePasswordRequest{CurrentPassword: "wrong password", NewPassword: "another password"}
+
+	// Текущий пароль подбирают с чужой сессией: неверные попытки считаются вместе со входом и блокируют аккаунт.
+	for i := 0; i < config.LockoutConfig().MaxFailures; i++ {
+		if _, err := a.ChangePassword(ctx, claims, req, "", testIP); !errors.Is(err, errors2.PasswordWrong) {
+			t.Fatalf("попытка %d: ошибка %v, ожидалась %v", i+1, err, errors2.PasswordWrong)
+		}
+	}
+
+	req.CurrentPassword = testPassword
+	if _, err := a.ChangePassword(ctx, claims, req, "", testIP); !errors.Is(err, errors2.ErrAccountLocked) {
+		t.Fatalf("ошибка %v, ожидалась %v", err, errors2.ErrAccountLocked)
+	}
+	if _, _, err := a.Authentication(ctx, &dto.SignInRequest{Email: testEmail, Password: testPassword}, "", testIP); !errors.Is(err, errors2.ErrAccountLocked) {
+		t.Fatalf("вход: ошибка %v, ожидалась %v", err, errors2.ErrAccountLocked)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/dto"
+	"context"
+	"log/slog"
+)
+
+// ListSessions - возвращает активные сессии пользователя, отмечая ту, из которой сделан запрос.
+func (a *Auth) ListSessions(ctx context.Context, claims *dto.AccessToken) ([]dto.Session, error) {
+	tokens, err := a.jwtRepo.ListActiveRefreshTokens(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]dto.Session, 0, len(tokens))
+	for _, t := range tokens {
+		sessions = append(sessions, dto.Session{
+			ID:         t.FamilyID,
+			DeviceInfo: t.DeviceInfo,
+			IPAddress:  t.IPAddress,
+			CreatedAt:  t.CreatedAt,
+			ExpiresAt:  t.ExpiresAt,
+			Current:    t.FamilyID == claims.Sid,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession - завершает одну сессию пользователя и блокирует выданные в ней Access токены.
+func (a *Auth) RevokeSession(ctx context.Context, claims *dto.AccessToken, sessionID string) error {
+	revoked, err := a.revokeFamily(ctx, claims.UserID, sessionID)
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return errors2.ErrSessionNotFound
+	}
+
+	slog.Info("Сессия пользователя завершена", "user_id", claims.UserID, "session_id", sessionID)
+	return nil
+}
+
+// RevokeOtherSessions - завершает все сессии пользователя, кроме текущей, и блокирует выданные в них Access токены.
+// Возвращает количество завершённых.
+func (a *Auth) RevokeOtherSessions(ctx context.Context, claims *dto.AccessToken) (int, error) {
+	count, err := a.revokeSessions(ctx, claims.UserID, claims.Sid)
+	if err != nil {
+		return 0, err
+	}
+
+	slog.Info("Завершены остальные сессии пользователя", "user_id", claims.UserID, "count", count)
+	return count, nil
+}
+
+// revokeSessions - завершает через revokeFamily все действующие сессии пользователя, кроме exceptFamilyID:
+// отзыва Refresh токенов недостаточно, уже выданные Access токены действуют до истечения.
+// Пустой exceptFamilyID - завершить все сессии. Возвращает количество завершённых.
+func (a *Auth) revokeSessions(ctx context.Context, userID int, exceptFamilyID string) (int, error) {
+	tokens, err := a.jwtRepo.ListActiveRefreshTokens(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := map[string]bool{}
+	for _, t := range tokens {
+		if t.FamilyID == exceptFamilyID || revoked[t.FamilyID] {
+			continue
+		}
+		if _, err := a.revokeFamily(ctx, userID, t.FamilyID); err != nil {
+			return len(revoked), err
+		}
+		revoked[t.FamilyID] = true
+	}
+
+	return len(revoked), nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"DBManager/internal/repository"
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/rbac"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// RecordMovement - проводит движение товара от имени пользователя из claims.
+// Запись журнала и изменение остатков сохраняются атомарно.
+func (m *Manager) RecordMovement(ctx context.Context, claims *dto.AccessToken, req *dto.MovementRequest) (*dto.StockMovement, error) {
+	// Корректировки меняют остатки без документа-основания, поэтому требуют отдельного права.
+	// Проверяется здесь, по тому же значению типа, по которому движение будет проведено.
+	if req.Type == dto.MovementAdjustment {
+		if err := Authorize(claims, rbac.PermStockAdjust); err != nil {
+			return nil, err
+		}
+	}
+
+	movement := dto.StockMovement{
+		Type:           req.Type,
+		ItemID:         req.ItemID,
+		FromLocationID: req.FromLocationID,
+		ToLocationID:   req.ToLocationID,
+		Quantity:       req.Quantity,
+		Reason:         req.Reason,
+		Reference:      req.Reference,
+		UserID:         claims.UserID,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := validateMovement(&movement); err != nil {
+		return nil, err
+	}
+
+	// Проверяем, что товар и места хранения существуют.
+	if _, err := m.GetItem(ctx, movement.ItemID); err != nil {
+		return nil, err
+	}
+	for _, locationID := range []*int{movement.FromLocationID, movement.ToLocationID} {
+		if locationID == nil {
+			continue
+		}
+		if _, err := m.repo.GetLocationByID(ctx, *locationID); err != nil {
+			if errors.Is(err, repository.RecordNotFound) {
+				return nil, errors2.ErrLocationNotFound
+			}
+			return nil, err
+		}
+	}
+
+	if err := m.repo.CreateMovement(ctx, &movement); err != nil {
+		if errors.Is(err, repository.NotEnoughStock) {
+			return nil, errors2.ErrInsufficientStock
+		}
+		return nil, err
+	}
+
+	return &movement, nil
+}
+
+// ListMovements - возвращает страницу журнала движений.
+func (m *Manager) ListMovements(ctx context.Context, filter *dto.MovementFilter) ([]dto.StockMovement, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultItemsLimit
+	}
+	if filter.Limit > maxItemsLimit {
+		filter.Limit = maxItemsLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return m.repo.ListMovements(ctx, filter)
+}
+
+// ReconcileStock - сверяет таблицу остатков с журналом движений и возвращает расхождения, ничего не меняя.
+// Если itemID равен 0 - по всем товарам.
+func (m *Manager) ReconcileStock(ctx context.Context, itemID int) ([]dto.StockDiscrepancy, error) {
+	balances, err := m.repo.GetLedgerBalances(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	levels, err := m.repo.GetStockLevels(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareStock(balances, levels), nil
+}
+
+// ApplyStockReconciliation - исправляет таблицу остатков по журналу движений от имени пользователя userID
+// и возвращает исправленные расхождения. Если itemID равен 0 - по всем товарам.
+// Сверка и исправление выполняются в одной транзакции: строки остатков блокируются до чтения журнала,
+// поэтому параллельное движение не может изменить их между сравнением и записью.
+// Каждое исправление записывается в журнал движением reconciliation.
+func (m *Manager) ApplyStockReconciliation(ctx context.Context, userID, itemID int) ([]dto.StockDiscrepancy, error) {
+	fixed := make([]dto.StockDiscrepancy, 0)
+
+	err := m.repo.WithTx(ctx, func(ctx context.Context) error {
+		levels, err := m.repo.LockStockLevels(ctx, itemID)
+		if err != nil {
+			return err
+		}
+
+		balances, err := m.repo.GetLedgerBalances(ctx, itemID)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range compareStock(balances, levels) {
+			now := time.Now()
+			applied, err := m.repo.SetStockLevel(ctx, &dto.StockLevel{
+				ItemID:     d.ItemID,
+				LocationID: d.LocationID,
+				Quantity:   d.LedgerQuantity,
+				UpdatedAt:  now,
+			}, reconciliationMovement(userID, &d, now))
+			if err != nil {
+				return err
+			}
+			if applied {
+				fixed = append(fixed, d)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fixed) > 0 {
+		slog.Warn("Остатки исправлены по журналу движений", "count", len(fixed), "user_id", userID)
+	}
+
+	return fixed, nil
+}
+
+// compareStock - расхождения между остатками по журналу и строками таблицы остатков.
+func compareStock(balances []dto.StockBalance, levels []dto.StockLevel) []dto.StockDiscrepancy {
+	type key struct{ itemID, locationID int }
+
+	ledger := make(map[key]dto.Quantity, len(balances))
+	for _, b := range balances {
+		ledger[key{b.ItemID, b.LocationID}] = b.Quantity
+	}
+
+	discrepancies := make([]dto.StockDiscrepancy, 0)
+	for _, l := range levels {
+		k := key{l.ItemID, l.LocationID}
+		if ledger[k] != l.Quantity {
+			discrepancies = append(discrepancies, dto.StockDiscrepancy{
+				ItemID:         l.ItemID,
+				LocationID:     l.LocationID,
+				LedgerQuantity: ledger[k],
+				StockQuantity:  l.Quantity,
+			})
+		}
+		delete(ledger, k)
+	}
+	// Оставшиеся в карте остатки есть в журнале, но отсутствуют в таблице остатков.
+	for k, quantity := range ledger {
+		if quantity != 0 {
+			discrepancies = append(discrepancies, dto.StockDiscrepancy{
+				ItemID:         k.itemID,
+				LocationID:     k.locationID,
+				LedgerQuantity: quantity,
+			})
+		}
+	}
+
+	return discrepancies
+}
+
+// reconciliationMovement - запись журнала об исправлении остатка: увеличение оформляется как поступление
+// в место хранения, уменьшение - как списание из него, на величину расхождения.
+func reconciliationMovement(userID int, d *dto.StockDiscrepancy, now time.Time) *dto.StockMovement {
+	movement := &dto.StockMovement{
+		Type:      dto.MovementReconciliation,
+		ItemID:    d.ItemID,
+		Quantity:  (d.LedgerQuantity - d.StockQuantity).Abs(),
+		Reason:    "Исправление остатка по журналу движений",
+		UserID:    userID,
+		CreatedAt: now,
+	}
+
+	locationID := d.LocationID
+	if d.LedgerQuantity > d.StockQuantity {
+		movement.ToLocationID = &locationID
+	} else {
+		movement.FromLocationID = &locationID
+	}
+
+	return movement
+}
+
+// validateMovement - проверяет, что набор мест хранения соответствует типу движения.
+func validateMovement(movement *dto.StockMovement) error {
+	if movement.ItemID == 0 || movement.Quantity <= 0 {
+		return errors2.ErrInvalidMovementFormat
+	}
+
+	from, to := movement.FromLocationID != nil, movement.ToLocationID != nil
+
+	switch movement.Type {
+	case dto.MovementReceipt:
+		if from || !to {
+			return errors2.ErrInvalidMovementFormat
+		}
+	case dto.MovementIssue:
+		if !from || to {
+			return errors2.ErrInvalidMovementFormat
+		}
+	case dto.MovementTransfer:
+		if !from || !to || *movement.FromLocationID == *movement.ToLocationID {
+			return errors2.ErrInvalidMovementFormat
+		}
+	case dto.MovementAdjustment:
+		// Излишек оформляется как поступление в место хранения, недостача - как списание из него.
+		if from == to {
+			return errors2.ErrInvalidMovementFormat
+		}
+	default:
+		return errors2.ErrInvalidMovementFormat
+	}
+
+	return nil
+}
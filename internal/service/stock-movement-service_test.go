@@ -0,0 +1,35 @@
+package service
+
+import (
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/rbac"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRecordAdjustmentRequiresPermission(t *testing.T) {
+	// Репозиторий не нужен: без права на корректировку сервис отказывает до обращения к нему.
+	m := NewManager(nil)
+	to := 1
+	req := &dto.MovementRequest{Type: dto.MovementAdjustment, ItemID: 1, ToLocationID: &to, Quantity: 1000}
+
+	tests := []struct {
+		name   string
+		claims *dto.AccessToken
+	}{
+		{name: "роль без права", claims: &dto.AccessToken{UserID: 1, Role: string(rbac.RoleClerk)}},
+		{name: "API ключ без права", claims: &dto.AccessToken{
+			UserID: 1, Role: string(rbac.RoleAdmin), APIKeyID: 1, Scopes: []string{string(rbac.PermMovementsWrite)},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.RecordMovement(context.Background(), tt.claims, req); !errors.Is(err, errors2.ErrForbidden) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, errors2.ErrForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"DBManager/internal/repository"
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/dto"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// CreateWarehouse - регистрирует новую складскую площадку.
+func (m *Manager) CreateWarehouse(ctx context.Context, req *dto.CreateWarehouseRequest) (*dto.Warehouse, error) {
+	warehouse := dto.Warehouse{
+		Code:      strings.TrimSpace(req.Code),
+		Name:      strings.TrimSpace(req.Name),
+		Address:   req.Address,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if warehouse.Code == "" || warehouse.Name == "" {
+		return nil, errors2.ErrInvalidWarehouseFormat
+	}
+
+	if err := m.repo.CreateWarehouse(ctx, &warehouse); err != nil {
+		if errors.Is(err, repository.RecordAlreadyExist) {
+			return nil, errors2.ErrWarehouseCodeAlreadyExist
+		}
+		return nil, err
+	}
+
+	return &warehouse, nil
+}
+
+// ListWarehouses - возвращает список всех складов.
+func (m *Manager) ListWarehouses(ctx context.Context) ([]dto.Warehouse, error) {
+	return m.repo.ListWarehouses(ctx)
+}
+
+// CreateLocation - добавляет место хранения на существующий склад.
+func (m *Manager) CreateLocation(ctx context.Context, req *dto.CreateLocationRequest) (*dto.Location, error) {
+	location := dto.Location{
+		WarehouseID: req.WarehouseID,
+		Code:        strings.TrimSpace(req.Code),
+		Description: req.Description,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if location.WarehouseID == 0 || location.Code == "" {
+		return nil, errors2.ErrInvalidLocationFormat
+	}
+
+	if _, err := m.getWarehouse(ctx, location.WarehouseID); err != nil {
+		return nil, err
+	}
+
+	if err := m.repo.CreateLocation(ctx, &location); err != nil {
+		if errors.Is(err, repository.RecordAlreadyExist) {
+			return nil, errors2.ErrLocationCodeAlreadyExist
+		}
+		return nil, err
+	}
+
+	return &location, nil
+}
+
+// ListLocations - возвращает места хранения склада.
+func (m *Manager) ListLocations(ctx context.Context, warehouseID int) ([]dto.Location, error) {
+	if _, err := m.getWarehouse(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+
+	return m.repo.ListLocations(ctx, warehouseID)
+}
+
+// GetItemStock - возвращает остаток товара по всем складам.
+func (m *Manager) GetItemStock(ctx context.Context, itemID int) (*dto.ItemStock, error) {
+	if _, err := m.GetItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+
+	return m.itemStock(ctx, itemID, 0)
+}
+
+// GetItemStockInWarehouse - возвращает остаток товара на одном складе.
+func (m *Manager) GetItemStockInWarehouse(ctx context.Context, itemID, warehouseID int) (*dto.ItemStock, error) {
+	if _, err := m.GetItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+	if _, err := m.getWarehouse(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+
+	return m.itemStock(ctx, itemID, warehouseID)
+}
+
+// itemStock - собирает остатки по местам хранения и считает итог.
+func (m *Manager) itemStock(ctx context.Context, itemID, warehouseID int) (*dto.ItemStock, error) {
+	locations, err := m.repo.GetItemStock(ctx, itemID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := dto.ItemStock{
+		ItemID:      itemID,
+		WarehouseID: warehouseID,
+		Locations:   locations,
+	}
+	for _, l := range locations {
+		stock.OnHand += l.Quantity
+	}
+
+	return &stock, nil
+}
+
+// getWarehouse - получает склад, приводя ошибку отсутствия к ошибке сервиса.
+func (m *Manager) getWarehouse(ctx context.Context, warehouseID int) (*dto.Warehouse, error) {
+	warehouse, err := m.repo.GetWarehouseByID(ctx, warehouseID)
+	if err != nil {
+		if errors.Is(err, repository.RecordNotFound) {
+			return nil, errors2.ErrWarehouseNotFound
+		}
+		return nil, err
+	}
+
+	return warehouse, nil
+}
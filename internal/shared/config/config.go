@@ -2,59 +2,120 @@ package config
 
 import (
 	"DBManager/internal/shared/dto/config"
-	"log"
-	"os"
+	"golang.org/x/crypto/bcrypt"
 	"time"
 )
 
-var (
-	accessTTL  = time.Minute * 15
-	refreshTTL = time.Hour * 24 * 7
-)
+// current - конфигурация, загруженная Load. До загрузки nil.
+var current *config.Config
 
-func PgSQLConfig() (*config.PostgresConfig, error) {
-	addr := os.Getenv("POSTGRES_CONNECT_STRING")
+// Default - значения по умолчанию, которые переопределяются файлом, окружением и флагами.
+func Default() *config.Config {
+	return &config.Config{
+		HTTP: config.HTTPConfig{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		Token: config.TokenConfig{
+			AccessTTL:  time.Minute * 15,
+			RefreshTTL: time.Hour * 24 * 7,
+			ResetTTL:   time.Minute * 30,
+			VerifyTTL:  time.Hour * 24,
+		},
+		Mailer: config.MailerConfig{
+			PublicURL: "http://localhost:8080",
+		},
+		Auth: config.AuthConfig{
+			UnverifiedLogin:    config.UnverifiedLoginLimit,
+			VerificationResend: time.Minute,
+			MFAIssuer:          "ManagerInventory",
+			MFAChallengeTTL:    time.Minute * 5,
+		},
+		OIDC: config.OIDCConfig{
+			// По умолчанию запрашиваем только то, что нужно для поиска пользователя: email и имя.
+			Scopes:      []string{"openid", "email", "profile"},
+			StateTTL:    time.Minute * 10,
+			HTTPTimeout: 10 * time.Second,
+		},
+		Lockout: config.LockoutConfig{
+			MaxFailures:   5,
+			MaxIPFailures: 50,
+			Window:        15 * time.Minute,
+			LockoutFor:    15 * time.Minute,
+			DelayAfter:    3,
+			MaxDelay:      30 * time.Second,
+			BaseDelay:     time.Second,
+		},
+		Password: config.PasswordConfig{
+			MinLength: 8,
+			MaxLength: 64,
+			// Буква, цифра и спецсимвол - как и до появления настройки. none - без требований к классам.
+			RequiredClasses: []string{config.PasswordClassLetter, config.PasswordClassDigit, config.PasswordClassSpecial},
+			HistorySize:     5,
 
-	if addr == "" {
-		log.Fatal("Не удалось получить данные для подключения к Postgres из файла .env")
+			// Параметры Argon2id по умолчанию - рекомендация OWASP: 19 МиБ памяти, 2 прохода, 1 поток.
+			HashAlgorithm:     config.PasswordHashArgon2id,
+			Argon2Memory:      19 * 1024,
+			Argon2Iterations:  2,
+			Argon2Parallelism: 1,
+			BcryptCost:        bcrypt.DefaultCost,
+		},
+		RateLimit: config.RateLimitConfig{
+			Auth:  config.RateLimitPolicy{Name: "auth", Limit: 20, Period: time.Minute},
+			Email: config.RateLimitPolicy{Name: "email", Limit: 5, Period: 10 * time.Minute},
+			API:   config.RateLimitPolicy{Name: "api", Limit: 300, Period: time.Minute},
+		},
 	}
-
-	return &config.PostgresConfig{
-		Addr: addr,
-	}, nil
 }
 
-func RedisConfig() *config.RedisConfig {
-	addr := os.Getenv("REDIS_ADDR")
-	pass := os.Getenv("REDIS_PASS")
-
-	if addr == "" || pass == "" {
-		log.Fatal("Не удалось получить данные для подключения к Redis из файла .env")
+// Get - конфигурация, загруженная при старте. Обращение до Load - ошибка в порядке запуска приложения.
+func Get() *config.Config {
+	if current == nil {
+		panic("конфигурация не загружена: config.Load должен вызываться при старте приложения")
 	}
+	return current
+}
 
-	return &config.RedisConfig{
-		Addr: addr,
-		Pass: pass,
-	}
+func PgSQLConfig() *config.PostgresConfig {
+	return &Get().Postgres
+}
+
+func RedisConfig() *config.RedisConfig {
+	return &Get().Redis
 }
 
 func TokenConfig() *config.TokenConfig {
-	accessSecret := os.Getenv("ACCESS_SECRET")
-	refreshSecret := os.Getenv("REFRESH_SECRET")
-
-	return &config.TokenConfig{
-		AccessSecret:  accessSecret,
-		RefreshSecret: refreshSecret,
-		AccessTTL:     accessTTL,
-		RefreshTTL:    refreshTTL,
-	}
+	return &Get().Token
 }
 
 func HTTPConfig() *config.HTTPConfig {
-	httpAddr := os.Getenv("HTTP_ADDR")
-	if httpAddr == "" {
-		httpAddr = ":8080"
-	}
+	return &Get().HTTP
+}
+
+func MailerConfig() *config.MailerConfig {
+	return &Get().Mailer
+}
+
+func AuthConfig() *config.AuthConfig {
+	return &Get().Auth
+}
+
+func OIDCConfig() *config.OIDCConfig {
+	return &Get().OIDC
+}
+
+func LockoutConfig() *config.LockoutConfig {
+	return &Get().Lockout
+}
+
+func PasswordConfig() *config.PasswordConfig {
+	return &Get().Password
+}
 
-	return &config.HTTPConfig{Addr: httpAddr}
+func RateLimitConfig() *config.RateLimitConfig {
+	return &Get().RateLimit
 }
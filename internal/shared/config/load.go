@@ -0,0 +1,299 @@
+package config
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Load - загружает конфигурацию: значения по умолчанию, затем файл (флаг -config или CONFIG_FILE),
+// переменные окружения и флаги из args. Проверяет результат и сохраняет его для Get и геттеров разделов.
+// Флаги регистрируются в fs, так что подкоманда может добавить к ним свои.
+func Load(fs *flag.FlagSet, args []string) (*config.Config, error) {
+	cfg, err := Read(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	current = cfg
+	return cfg, nil
+}
+
+// Read - собирает конфигурацию из тех же источников, что и Load, но не проверяет её и не сохраняет для Get.
+// Ошибкой остаются только значения, которые не разбираются в тип поля.
+func Read(fs *flag.FlagSet, args []string) (*config.Config, error) {
+	cfg := Default()
+	fields := fieldsOf(cfg)
+
+	// Флаги разбираются первыми, чтобы узнать путь к файлу, а применяются последними - они важнее всего.
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "файл конфигурации YAML или TOML (CONFIG_FILE)")
+	flagValues := map[*field]string{}
+	for _, f := range fields {
+		f := f
+		fs.Func(f.flagName(), f.env, func(raw string) error {
+			flagValues[f] = raw
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(*configFile, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for _, f := range fields {
+		// Пустая переменная - то же, что отсутствующая: docker compose передаёт незаданные переменные пустыми.
+		if raw := os.Getenv(f.env); raw != "" {
+			if err := f.set(raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.env, err))
+			}
+		}
+	}
+	for _, f := range fields {
+		if raw, ok := flagValues[f]; ok {
+			if err := f.set(raw); err != nil {
+				errs = append(errs, fmt.Errorf("-%s: %w", f.flagName(), err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	normalize(cfg)
+	return cfg, nil
+}
+
+// field - настраиваемое поле конфигурации.
+type field struct {
+	section string // Раздел в файле
+	key     string // Ключ в разделе
+	env     string // Переменная окружения
+	secret  bool   // Скрывается при печати
+	zeroOff bool   // Ноль допустим и выключает настройку
+	value   reflect.Value
+}
+
+// fieldsOf - поля конфигурации в порядке объявления: разделы Config и поля разделов с тегом conf.
+func fieldsOf(cfg *config.Config) []*field {
+	var fields []*field
+
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Type().Field(i).Tag.Get("conf")
+		value := root.Field(i)
+
+		for j := 0; j < value.NumField(); j++ {
+			sf := value.Type().Field(j)
+			key := sf.Tag.Get("conf")
+			if key == "" {
+				continue
+			}
+			fields = append(fields, &field{
+				section: section,
+				key:     key,
+				env:     sf.Tag.Get("env"),
+				secret:  sf.Tag.Get("secret") == "true",
+				zeroOff: sf.Tag.Get("zero") == "off",
+				value:   value.Field(j),
+			})
+		}
+	}
+
+	return fields
+}
+
+// flagName - имя флага из переменной окружения: ACCESS_TTL -> access-ttl.
+func (f *field) flagName() string {
+	return strings.ToLower(strings.ReplaceAll(f.env, "_", "-"))
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	rateLimitPolicyType = reflect.TypeOf(config.RateLimitPolicy{})
+)
+
+// errInvalidValue - значение не разбирается в тип поля.
+var errInvalidValue = errors.New("некорректное значение")
+
+// set - разбирает строковое значение в тип поля. Числа и длительности должны быть положительными,
+// кроме целых полей с тегом zero:"off" - для них 0 выключает настройку. Списки - через запятую или пробел,
+// none - пустой список.
+func (f *field) set(raw string) error {
+	invalid := fmt.Errorf("%w %q", errInvalidValue, raw)
+
+	switch f.value.Type() {
+	case durationType:
+		value, err := time.ParseDuration(raw)
+		if err != nil || value <= 0 {
+			return invalid
+		}
+		f.value.SetInt(int64(value))
+		return nil
+	case rateLimitPolicyType:
+		limit, period, ok := strings.Cut(raw, "/")
+		value, err := strconv.Atoi(limit)
+		duration, durErr := time.ParseDuration(period)
+		if !ok || err != nil || durErr != nil || value <= 0 || duration <= 0 {
+			return invalid
+		}
+		// Имя политики не настраивается: оно задано в Default и используется в ключах счётчиков.
+		policy := f.value.Addr().Interface().(*config.RateLimitPolicy)
+		policy.Limit, policy.Period = value, duration
+		return nil
+	}
+
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(raw)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return invalid
+		}
+		f.value.SetBool(value)
+	case reflect.Int:
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 || value == 0 && !f.zeroOff {
+			return invalid
+		}
+		f.value.SetInt(int64(value))
+	case reflect.Uint8, reflect.Uint32:
+		value, err := strconv.ParseUint(raw, 10, f.value.Type().Bits())
+		if err != nil || value == 0 {
+			return invalid
+		}
+		f.value.SetUint(value)
+	case reflect.Slice:
+		items := []string{}
+		if raw != "none" {
+			items = append(items, strings.Fields(strings.ReplaceAll(raw, ",", " "))...)
+		}
+		f.value.Set(reflect.ValueOf(items))
+	default:
+		panic(fmt.Sprintf("неподдерживаемый тип поля конфигурации %s.%s: %s", f.section, f.key, f.value.Type()))
+	}
+	return nil
+}
+
+// loadFile - применяет значения из файла. Формат определяется по расширению: .yaml, .yml или .toml.
+// Файл состоит из разделов с ключами, как в Config, неизвестные разделы и ключи - ошибка: скорее всего, опечатка.
+func loadFile(path string, fields []*field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать файл конфигурации: %w", err)
+	}
+
+	var raw map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("неизвестный формат файла конфигурации %s: ожидается .yaml, .yml или .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("не удалось разобрать файл конфигурации %s: %w", path, err)
+	}
+
+	index := map[string]*field{}
+	sections := map[string]bool{}
+	for _, f := range fields {
+		index[f.section+"."+f.key] = f
+		sections[f.section] = true
+	}
+
+	// Ключи обходятся по порядку, чтобы ошибки выводились одинаково от запуска к запуску.
+	var errs []error
+	for _, section := range sortedKeys(raw) {
+		keys, ok := raw[section].(map[string]any)
+		if !sections[section] || !ok {
+			errs = append(errs, fmt.Errorf("%s: неизвестный раздел %s", path, section))
+			continue
+		}
+
+		for _, key := range sortedKeys(keys) {
+			value := keys[key]
+			name := section + "." + key
+			f, ok := index[name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: неизвестный ключ %s", path, name))
+				continue
+			}
+
+			str, err := fileValue(value)
+			if err == nil {
+				err = f.set(str)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", path, name, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fileValue - значение из файла в виде строки, как в переменной окружения. Список - через запятую.
+func fileValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			str, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, str)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		return "", fmt.Errorf("%w: ожидается значение, а не раздел", errInvalidValue)
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// normalize - приводит значения к виду, который ожидают потребители.
+func normalize(cfg *config.Config) {
+	cfg.OIDC.Issuer = strings.TrimSuffix(cfg.OIDC.Issuer, "/")
+
+	// openid запрашивается всегда и первым, даже если его не указали.
+	scopes := []string{"openid"}
+	for _, scope := range cfg.OIDC.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+	cfg.OIDC.Scopes = scopes
+}
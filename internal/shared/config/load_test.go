@@ -0,0 +1,184 @@
+package config
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// read - собирает конфигурацию из args на чистом наборе флагов.
+func read(t *testing.T, args ...string) (*config.Config, error) {
+	t.Helper()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return Read(fs, args)
+}
+
+// writeConfigFile - файл конфигурации во временном каталоге теста.
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadPrecedence(t *testing.T) {
+	file := writeConfigFile(t, "config.yaml", "token:\n  access_ttl: 20m\n")
+
+	tests := []struct {
+		name string
+		file bool
+		env  string
+		flag string
+		want time.Duration
+	}{
+		{name: "по умолчанию", want: 15 * time.Minute},
+		{name: "файл", file: true, want: 20 * time.Minute},
+		{name: "окружение важнее файла", file: true, env: "25m", want: 25 * time.Minute},
+		{name: "флаг важнее окружения", file: true, env: "25m", flag: "30m", want: 30 * time.Minute},
+		{name: "флаг без файла и окружения", flag: "30m", want: 30 * time.Minute},
+		{name: "пустая переменная не переопределяет файл", file: true, env: "", want: 20 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", "")
+			t.Setenv("ACCESS_TTL", tt.env)
+
+			var args []string
+			if tt.file {
+				args = append(args, "-config", file)
+			}
+			if tt.flag != "" {
+				args = append(args, "-access-ttl", tt.flag)
+			}
+
+			cfg, err := read(t, args...)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if cfg.Token.AccessTTL != tt.want {
+				t.Fatalf("ACCESS_TTL = %s, ожидалось %s", cfg.Token.AccessTTL, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadConfigFileFromEnv(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, "config.toml", "[lockout]\nmax_failures = 7\n"))
+
+	cfg, err := read(t)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if cfg.Lockout.MaxFailures != 7 {
+		t.Fatalf("LOGIN_MAX_FAILURES = %d, ожидалось 7", cfg.Lockout.MaxFailures)
+	}
+}
+
+func TestReadZeroOff(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		args        []string
+		wantHistory int
+		wantDelay   int
+		wantErr     bool
+	}{
+		{name: "не заданы - значения по умолчанию", wantHistory: 5, wantDelay: 3},
+		{name: "пустые переменные - значения по умолчанию", env: map[string]string{"PASSWORD_HISTORY": "", "LOGIN_DELAY_AFTER": ""}, wantHistory: 5, wantDelay: 3},
+		{name: "0 в окружении выключает", env: map[string]string{"PASSWORD_HISTORY": "0", "LOGIN_DELAY_AFTER": "0"}, wantHistory: 0, wantDelay: 0},
+		{name: "0 во флагах выключает", args: []string{"-password-history", "0", "-login-delay-after", "0"}, wantHistory: 0, wantDelay: 0},
+		{name: "положительные значения", env: map[string]string{"PASSWORD_HISTORY": "10", "LOGIN_DELAY_AFTER": "1"}, wantHistory: 10, wantDelay: 1},
+		{name: "отрицательное значение", env: map[string]string{"PASSWORD_HISTORY": "-1"}, wantErr: true},
+		{name: "0 без zero:off - ошибка", env: map[string]string{"LOGIN_MAX_FAILURES": "0"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"PASSWORD_HISTORY", "LOGIN_DELAY_AFTER", "LOGIN_MAX_FAILURES"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			cfg, err := read(t, tt.args...)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidValue) {
+					t.Fatalf("ошибка %v, ожидалась %v", err, errInvalidValue)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if cfg.Password.HistorySize != tt.wantHistory || cfg.Lockout.DelayAfter != tt.wantDelay {
+				t.Fatalf("PASSWORD_HISTORY = %d, LOGIN_DELAY_AFTER = %d, ожидалось %d и %d",
+					cfg.Password.HistorySize, cfg.Lockout.DelayAfter, tt.wantHistory, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		check   func(cfg *config.Config) bool
+		wantErr bool
+	}{
+		{
+			name:    "YAML со списком и нулём",
+			file:    "config.yaml",
+			content: "password:\n  required_classes: [upper, digit]\n  history: 0\noidc:\n  issuer: https://id.example.com/\n",
+			check: func(cfg *config.Config) bool {
+				return slices.Equal(cfg.Password.RequiredClasses, []string{"upper", "digit"}) &&
+					cfg.Password.HistorySize == 0 && cfg.OIDC.Issuer == "https://id.example.com"
+			},
+		},
+		{
+			name:    "TOML с политикой частоты",
+			file:    "config.toml",
+			content: "[rate_limit]\nauth = \"10/30s\"\n",
+			check: func(cfg *config.Config) bool {
+				return cfg.RateLimit.Auth == config.RateLimitPolicy{Name: "auth", Limit: 10, Period: 30 * time.Second}
+			},
+		},
+		{name: "неизвестный ключ", file: "config.yaml", content: "token:\n  acces_ttl: 1m\n", wantErr: true},
+		{name: "неизвестный раздел", file: "config.yaml", content: "tokens:\n  access_ttl: 1m\n", wantErr: true},
+		{name: "раздел вместо значения", file: "config.yaml", content: "token:\n  access_ttl:\n    value: 1m\n", wantErr: true},
+		{name: "некорректное значение", file: "config.yaml", content: "token:\n  access_ttl: soon\n", wantErr: true},
+		{name: "неизвестный формат", file: "config.json", content: "{}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := read(t, "-config", writeConfigFile(t, tt.file, tt.content))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ожидалась ошибка")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if !tt.check(cfg) {
+				t.Fatalf("значения из файла не применены: %+v", cfg)
+			}
+		})
+	}
+}
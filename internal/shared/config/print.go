@@ -0,0 +1,81 @@
+package config
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redactedValue - чем заменяются секреты при печати с redacted.
+const redactedValue = "[REDACTED]"
+
+// Print - печатает конфигурацию в YAML, в формате файла конфигурации: вывод можно сохранить и загрузить обратно.
+// С redacted заданные секреты заменяются на [REDACTED], пустые остаются пустыми - видно, что секрет не задан.
+func Print(w io.Writer, cfg *config.Config, redacted bool) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	var section *yaml.Node
+
+	for _, f := range fieldsOf(cfg) {
+		if section == nil || root.Content[len(root.Content)-2].Value != f.section {
+			section = &yaml.Node{Kind: yaml.MappingNode}
+			root.Content = append(root.Content, scalar(f.section), section)
+		}
+
+		value := f.node()
+		if redacted && f.secret && f.value.String() != "" {
+			value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: redactedValue}
+		}
+		// Переменная окружения - в комментарии, чтобы по выводу было понятно, чем переопределить значение.
+		value.LineComment = f.env
+		section.Content = append(section.Content, scalar(f.key), value)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// node - значение поля в том же виде, в каком оно задаётся в файле и окружении.
+func (f *field) node() *yaml.Node {
+	switch v := f.value.Interface().(type) {
+	case time.Duration:
+		return scalar(formatDuration(v))
+	case config.RateLimitPolicy:
+		return scalar(fmt.Sprintf("%d/%s", v.Limit, formatDuration(v.Period)))
+	case []string:
+		list := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, item := range v {
+			list.Content = append(list.Content, scalar(item))
+		}
+		return list
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	case bool:
+		return scalar(strconv.FormatBool(v))
+	default:
+		return scalar(fmt.Sprint(v))
+	}
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
+
+// formatDuration - длительность без нулевых хвостов: 15m вместо 15m0s, 168h вместо 168h0m0s.
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
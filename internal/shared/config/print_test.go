@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintRedacted(t *testing.T) {
+	cfg := validConfig()
+	cfg.Token.AccessSecret = "access-secret-value-access-secret-value"
+	cfg.Token.RefreshSecret = ""
+
+	tests := []struct {
+		name     string
+		redacted bool
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "секреты скрыты",
+			redacted: true,
+			want:     []string{"access_secret: '" + redactedValue + "' # ACCESS_SECRET", `refresh_secret: "" # REFRESH_SECRET`},
+			notWant:  []string{cfg.Token.AccessSecret, cfg.Redis.Pass},
+		},
+		{
+			name: "секреты показаны",
+			want: []string{"access_secret: " + cfg.Token.AccessSecret, "pass: " + cfg.Redis.Pass},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := Print(&out, cfg, tt.redacted); err != nil {
+				t.Fatalf("Print: %v", err)
+			}
+
+			for _, s := range tt.want {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("в выводе нет %q:\n%s", s, out.String())
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(out.String(), s) {
+					t.Errorf("в выводе есть %q:\n%s", s, out.String())
+				}
+			}
+		})
+	}
+}
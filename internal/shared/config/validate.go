@@ -0,0 +1,110 @@
+package config
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"DBManager/internal/shared/utils"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// minSecretLength - минимальная длина секрета подписи HS256 в байтах: ключ короче выхода SHA-256 ослабляет подпись.
+const minSecretLength = 32
+
+// Validate - проверяет конфигурацию целиком и возвращает все найденные ошибки сразу,
+// чтобы при развёртывании не исправлять их по одной. Положительность чисел и длительностей проверяется при разборе.
+func Validate(cfg *config.Config) error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validHostPort(cfg.HTTP.Addr), "HTTP_ADDR: ожидается адрес вида host:port, получено %q", cfg.HTTP.Addr)
+	check(cfg.HTTP.ReadHeaderTimeout <= cfg.HTTP.ReadTimeout,
+		"HTTP_READ_HEADER_TIMEOUT (%s) больше HTTP_READ_TIMEOUT (%s)", cfg.HTTP.ReadHeaderTimeout, cfg.HTTP.ReadTimeout)
+	if _, err := utils.ParseTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("TRUSTED_PROXIES: %w", err))
+	}
+	// Вход через OIDC обращается к провайдеру во время запроса: ответ должен успеть уйти после таймаута провайдера.
+	check(cfg.OIDC.Issuer == "" || cfg.OIDC.HTTPTimeout < cfg.HTTP.WriteTimeout,
+		"OIDC_HTTP_TIMEOUT (%s) должен быть меньше HTTP_WRITE_TIMEOUT (%s)", cfg.OIDC.HTTPTimeout, cfg.HTTP.WriteTimeout)
+
+	check(cfg.Postgres.Addr != "", "POSTGRES_CONNECT_STRING: не задана строка подключения к Postgres")
+
+	check(validHostPort(cfg.Redis.Addr), "REDIS_ADDR: ожидается адрес вида host:port, получено %q", cfg.Redis.Addr)
+	check(cfg.Redis.Pass != "", "REDIS_PASS: не задан пароль Redis")
+
+	// Без файла ключей access токены подписываются HS256 секретом ACCESS_SECRET.
+	if cfg.Token.KeysFile == "" {
+		check(len(cfg.Token.AccessSecret) >= minSecretLength,
+			"ACCESS_SECRET: без JWT_KEYS_FILE нужен секрет не короче %d байт", minSecretLength)
+	}
+	check(cfg.Token.RefreshSecret == "" || len(cfg.Token.RefreshSecret) >= minSecretLength,
+		"REFRESH_SECRET: секрет должен быть не короче %d байт", minSecretLength)
+	check(cfg.Token.AccessTTL < cfg.Token.RefreshTTL,
+		"ACCESS_TTL (%s) должен быть меньше REFRESH_TTL (%s)", cfg.Token.AccessTTL, cfg.Token.RefreshTTL)
+
+	check(validURL(cfg.Mailer.PublicURL), "PUBLIC_URL: ожидается абсолютный http(s) адрес, получено %q", cfg.Mailer.PublicURL)
+	switch cfg.Mailer.Driver {
+	case config.MailerDriverSMTP:
+		check(validHostPort(cfg.Mailer.SMTPAddr), "SMTP_ADDR: ожидается адрес вида host:port, получено %q", cfg.Mailer.SMTPAddr)
+	case config.MailerDriverFile, config.MailerDriverMemory:
+	default:
+		errs = append(errs, fmt.Errorf("MAILER_DRIVER: ожидается smtp, file или memory, получено %q", cfg.Mailer.Driver))
+	}
+
+	switch cfg.Auth.UnverifiedLogin {
+	case config.UnverifiedLoginAllow, config.UnverifiedLoginLimit, config.UnverifiedLoginDeny:
+	default:
+		errs = append(errs, fmt.Errorf("UNVERIFIED_LOGIN_POLICY: некорректное значение %q", cfg.Auth.UnverifiedLogin))
+	}
+
+	if cfg.OIDC.Issuer != "" {
+		check(validURL(cfg.OIDC.Issuer), "OIDC_ISSUER: ожидается абсолютный http(s) адрес, получено %q", cfg.OIDC.Issuer)
+		check(cfg.OIDC.ClientID != "" && cfg.OIDC.RedirectURL != "",
+			"для входа через OIDC необходимо указать OIDC_CLIENT_ID и OIDC_REDIRECT_URL")
+		check(cfg.OIDC.RedirectURL == "" || validURL(cfg.OIDC.RedirectURL),
+			"OIDC_REDIRECT_URL: ожидается абсолютный http(s) адрес, получено %q", cfg.OIDC.RedirectURL)
+	}
+
+	check(cfg.Password.MinLength <= cfg.Password.MaxLength,
+		"PASSWORD_MIN_LENGTH (%d) больше PASSWORD_MAX_LENGTH (%d)", cfg.Password.MinLength, cfg.Password.MaxLength)
+	for _, class := range cfg.Password.RequiredClasses {
+		switch class {
+		case config.PasswordClassLower, config.PasswordClassUpper, config.PasswordClassLetter,
+			config.PasswordClassDigit, config.PasswordClassSpecial:
+		default:
+			errs = append(errs, fmt.Errorf("PASSWORD_REQUIRED_CLASSES: некорректный класс символов %q", class))
+		}
+	}
+	switch cfg.Password.HashAlgorithm {
+	case config.PasswordHashArgon2id, config.PasswordHashBcrypt:
+	default:
+		errs = append(errs, fmt.Errorf("PASSWORD_HASH_ALGORITHM: некорректное значение %q", cfg.Password.HashAlgorithm))
+	}
+	check(cfg.Password.BcryptCost >= bcrypt.MinCost && cfg.Password.BcryptCost <= bcrypt.MaxCost,
+		"BCRYPT_COST: ожидается значение от %d до %d", bcrypt.MinCost, bcrypt.MaxCost)
+
+	return errors.Join(errs...)
+}
+
+// validHostPort - адрес вида host:port, host может быть пустым (все интерфейсы).
+func validHostPort(addr string) bool {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+// validURL - абсолютный адрес со схемой http или https.
+func validURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
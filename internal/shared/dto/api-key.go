@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// APIKey - ключ доступа для интеграций (сканеры, синхронизация с ERP).
+// Ключ вида mik_<prefix>_<secret>: по prefix запись находится в БД, от secret хранится только SHA-256 хэш.
+// Права ключа - пересечение его scopes с правами роли владельца.
+type APIKey struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"` // Владелец: пользователь или сервисная учётная запись
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	SecretHash string     `json:"-"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedBy  *int       `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	UserID    int        `json:"user_id"` // Ключ для другого пользователя может выпустить только администратор
+}
+
+// CreateAPIKeyResponse - ключ целиком показывается только один раз, при создании.
+type CreateAPIKeyResponse struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"api_key"`
+}
+
+type RevokeAPIKeyRequest struct {
+	ID int `json:"id" validate:"required,gt=0"`
+}
@@ -1,13 +1,49 @@
 package dto
 
+// SignInRequest - к паролю при входе политика не применяется: она могла измениться после регистрации.
 type SignInRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,max=1024"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,password"`
+}
+
+type RevokeSessionRequest struct {
+	SessionID string `json:"session_id" validate:"required"`
+}
+
+type UnlockUserRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ChangeRoleRequest struct {
+	UserID int    `json:"user_id" validate:"required,gt=0"`
+	Role   string `json:"role" validate:"required"`
 }
 
 type SignUpRequest struct {
-	FirstName string `json:"username"`
-	LastName  string `json:"last_name"`
-	Email     string `json:"email"`
-	Password  string `json:"password"`
+	FirstName string `json:"username" validate:"max=100"`
+	LastName  string `json:"last_name" validate:"max=100"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required,password"`
 }
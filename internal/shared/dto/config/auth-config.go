@@ -0,0 +1,19 @@
+package config
+
+import "time"
+
+// Политики входа для пользователей с неподтверждённым email.
+const (
+	UnverifiedLoginAllow = "allow" // Вход без ограничений
+	UnverifiedLoginLimit = "limit" // Вход с правами только на чтение
+	UnverifiedLoginDeny  = "deny"  // Вход запрещён до подтверждения
+)
+
+type AuthConfig struct {
+	UnverifiedLogin    string        `conf:"unverified_login" env:"UNVERIFIED_LOGIN_POLICY"`         // Политика входа без подтверждённого email
+	VerificationResend time.Duration `conf:"verification_resend" env:"VERIFICATION_RESEND_COOLDOWN"` // Минимальный интервал между повторными письмами подтверждения
+	SingleSession      bool          `conf:"single_session" env:"SINGLE_SESSION"`                    // Вход на новом устройстве завершает все остальные сессии
+	MFAIssuer          string        `conf:"mfa_issuer" env:"MFA_ISSUER"`                            // Название сервиса в приложении-аутентификаторе
+	MFAChallengeTTL    time.Duration `conf:"mfa_challenge_ttl" env:"MFA_CHALLENGE_TTL"`              // Сколько действует вызов второй ступени входа
+	MFAEnforced        bool          `conf:"mfa_enforce" env:"MFA_ENFORCE"`                          // Действия с остатками доступны только в сессиях со второй ступенью
+}
@@ -0,0 +1,19 @@
+package config
+
+// Config - вся конфигурация приложения. Загружается один раз при старте: значения по умолчанию,
+// затем файл конфигурации, переменные окружения и флаги командной строки - каждый следующий источник важнее.
+//
+// Теги полей описывают, откуда берётся значение: conf - раздел и ключ в файле, env - переменная окружения
+// (из неё же получается имя флага: ACCESS_TTL -> -access-ttl), secret - значение скрывается при печати конфигурации.
+type Config struct {
+	HTTP      HTTPConfig      `conf:"http"`
+	Postgres  PostgresConfig  `conf:"postgres"`
+	Redis     RedisConfig     `conf:"redis"`
+	Token     TokenConfig     `conf:"token"`
+	Mailer    MailerConfig    `conf:"mailer"`
+	Auth      AuthConfig      `conf:"auth"`
+	OIDC      OIDCConfig      `conf:"oidc"`
+	Lockout   LockoutConfig   `conf:"lockout"`
+	Password  PasswordConfig  `conf:"password"`
+	RateLimit RateLimitConfig `conf:"rate_limit"`
+}
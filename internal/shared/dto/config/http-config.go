@@ -1,5 +1,13 @@
 package config
 
+import "time"
+
 type HTTPConfig struct {
-	Addr string
+	Addr              string        `conf:"addr" env:"HTTP_ADDR"`
+	ReadHeaderTimeout time.Duration `conf:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"` // На чтение заголовков запроса, защищает от медленных клиентов
+	ReadTimeout       time.Duration `conf:"read_timeout" env:"HTTP_READ_TIMEOUT"`               // На чтение всего запроса вместе с телом
+	WriteTimeout      time.Duration `conf:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`             // От конца чтения заголовков до конца записи ответа
+	IdleTimeout       time.Duration `conf:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`               // Сколько держим keep-alive соединение без запросов
+	ShutdownTimeout   time.Duration `conf:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`       // Сколько ждём завершения начатых запросов при остановке
+	TrustedProxies    []string      `conf:"trusted_proxies" env:"TRUSTED_PROXIES"`              // IP и подсети прокси, которым доверяем X-Forwarded-For. Пусто - заголовок игнорируется
 }
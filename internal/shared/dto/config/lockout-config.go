@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+type LockoutConfig struct {
+	MaxFailures   int           `conf:"max_failures" env:"LOGIN_MAX_FAILURES"`          // Неудачных попыток входа в аккаунт до его временной блокировки
+	MaxIPFailures int           `conf:"max_ip_failures" env:"LOGIN_MAX_IP_FAILURES"`    // Неудачных попыток входа с одного IP до отказа в обслуживании
+	Window        time.Duration `conf:"failure_window" env:"LOGIN_FAILURE_WINDOW"`      // Скользящее окно, в котором считаются неудачные попытки
+	LockoutFor    time.Duration `conf:"lockout_duration" env:"LOGIN_LOCKOUT_DURATION"`  // Длительность временной блокировки аккаунта
+	DelayAfter    int           `conf:"delay_after" env:"LOGIN_DELAY_AFTER" zero:"off"` // С какой неудачной попытки начинается прогрессивная задержка, 0 - без задержки
+	MaxDelay      time.Duration `conf:"max_delay" env:"LOGIN_MAX_DELAY"`                // Предел прогрессивной задержки между попытками
+	BaseDelay     time.Duration `conf:"base_delay" env:"LOGIN_BASE_DELAY"`              // Первая задержка, каждая следующая вдвое больше
+}
@@ -0,0 +1,18 @@
+package config
+
+// Драйверы доставки писем.
+const (
+	MailerDriverSMTP   = "smtp"
+	MailerDriverFile   = "file"   // Файлы .eml в каталоге, для локальной разработки
+	MailerDriverMemory = "memory" // Память процесса, письма теряются при перезапуске - только для разработки и тестов
+)
+
+type MailerConfig struct {
+	Driver    string `conf:"driver" env:"MAILER_DRIVER"` // smtp, file или memory, обязателен
+	From      string `conf:"from" env:"MAIL_FROM"`       // Адрес отправителя
+	Dir       string `conf:"dir" env:"MAILER_DIR"`       // Каталог для писем драйвера file
+	SMTPAddr  string `conf:"smtp_addr" env:"SMTP_ADDR"`
+	SMTPUser  string `conf:"smtp_user" env:"SMTP_USER"`
+	SMTPPass  string `conf:"smtp_pass" env:"SMTP_PASS" secret:"true"`
+	PublicURL string `conf:"public_url" env:"PUBLIC_URL"` // Адрес фронтенда, на который ведут ссылки из писем
+}
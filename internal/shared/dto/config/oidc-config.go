@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// OIDCConfig - вход через внешнего провайдера OpenID Connect (корпоративный IdP).
+type OIDCConfig struct {
+	Issuer        string        `conf:"issuer" env:"OIDC_ISSUER"`                             // URL провайдера, по нему читается discovery документ. Пусто - вход через OIDC выключен
+	ClientID      string        `conf:"client_id" env:"OIDC_CLIENT_ID"`                       // Идентификатор приложения у провайдера
+	ClientSecret  string        `conf:"client_secret" env:"OIDC_CLIENT_SECRET" secret:"true"` // Секрет приложения. Пусто - публичный клиент, защищённый только PKCE
+	RedirectURL   string        `conf:"redirect_url" env:"OIDC_REDIRECT_URL"`                 // Адрес OIDCCallback, зарегистрированный у провайдера
+	Scopes        []string      `conf:"scopes" env:"OIDC_SCOPES"`                             // Запрашиваемые scope, openid добавляется всегда
+	AutoProvision bool          `conf:"auto_provision" env:"OIDC_AUTO_PROVISION"`             // Создавать пользователя при первом входе, если аккаунта с таким email нет
+	StateTTL      time.Duration `conf:"state_ttl" env:"OIDC_STATE_TTL"`                       // Сколько ждём возврата пользователя от провайдера
+	HTTPTimeout   time.Duration `conf:"http_timeout" env:"OIDC_HTTP_TIMEOUT"`                 // Таймаут запросов к провайдеру
+}
@@ -0,0 +1,30 @@
+package config
+
+// Классы символов, которые может требовать политика паролей.
+const (
+	PasswordClassLower   = "lower"   // Строчная буква
+	PasswordClassUpper   = "upper"   // Заглавная буква
+	PasswordClassLetter  = "letter"  // Любая буква
+	PasswordClassDigit   = "digit"   // Цифра
+	PasswordClassSpecial = "special" // Символ, не являющийся буквой, цифрой или пробелом
+)
+
+// Алгоритмы хэширования паролей.
+const (
+	PasswordHashArgon2id = "argon2id"
+	PasswordHashBcrypt   = "bcrypt"
+)
+
+type PasswordConfig struct {
+	MinLength       int      `conf:"min_length" env:"PASSWORD_MIN_LENGTH"`             // Минимальная длина в символах
+	MaxLength       int      `conf:"max_length" env:"PASSWORD_MAX_LENGTH"`             // Максимальная длина в символах
+	RequiredClasses []string `conf:"required_classes" env:"PASSWORD_REQUIRED_CLASSES"` // Классы символов, каждый из которых должен встретиться в пароле
+	HistorySize     int      `conf:"history" env:"PASSWORD_HISTORY" zero:"off"`        // Сколько прежних паролей нельзя использовать повторно, кроме текущего. 0 - история не ведётся
+	BreachedFile    string   `conf:"breached_file" env:"PASSWORD_BREACHED_FILE"`       // Файл с SHA-1 хэшами утёкших паролей, пусто - проверка выключена
+
+	HashAlgorithm     string `conf:"hash_algorithm" env:"PASSWORD_HASH_ALGORITHM"` // Алгоритм хэширования новых паролей
+	Argon2Memory      uint32 `conf:"argon2_memory" env:"ARGON2_MEMORY"`            // Память Argon2id в КиБ
+	Argon2Iterations  uint32 `conf:"argon2_iterations" env:"ARGON2_ITERATIONS"`    // Число проходов Argon2id
+	Argon2Parallelism uint8  `conf:"argon2_parallelism" env:"ARGON2_PARALLELISM"`  // Число потоков Argon2id
+	BcryptCost        int    `conf:"bcrypt_cost" env:"BCRYPT_COST"`                // Стоимость bcrypt
+}
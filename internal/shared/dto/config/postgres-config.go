@@ -1,5 +1,5 @@
 package config
 
 type PostgresConfig struct {
-	Addr string
+	Addr string `conf:"connect_string" env:"POSTGRES_CONNECT_STRING" secret:"true"` // Содержит пароль от БД
 }
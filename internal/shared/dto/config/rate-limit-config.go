@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+// RateLimitPolicy - не более Limit запросов за Period, допускается всплеск до Limit запросов подряд.
+// В конфигурации задаётся строкой "число/период", например 20/1m.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+}
+
+type RateLimitConfig struct {
+	Driver string          `conf:"driver" env:"RATE_LIMIT_DRIVER"` // redis или memory
+	Auth   RateLimitPolicy `conf:"auth" env:"RATE_LIMIT_AUTH"`     // Вход, регистрация, обновление токенов - по IP
+	Email  RateLimitPolicy `conf:"email" env:"RATE_LIMIT_EMAIL"`   // Запросы, отправляющие письма, - по IP
+	API    RateLimitPolicy `conf:"api" env:"RATE_LIMIT_API"`       // Запросы авторизованных пользователей - по ID пользователя
+}
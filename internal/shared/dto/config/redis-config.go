@@ -1,6 +1,6 @@
 package config
 
 type RedisConfig struct {
-	Addr string
-	Pass string
+	Addr string `conf:"addr" env:"REDIS_ADDR"`
+	Pass string `conf:"pass" env:"REDIS_PASS" secret:"true"`
 }
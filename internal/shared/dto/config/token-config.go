@@ -3,8 +3,11 @@ package config
 import "time"
 
 type TokenConfig struct {
-	AccessSecret  string        // Секрет для подписи access токенов
-	RefreshSecret string        // Секрет для подписи refresh токенов
-	AccessTTL     time.Duration // Время жизни access токена (15m)
-	RefreshTTL    time.Duration // Время жизни refresh токена (7d)
+	AccessSecret  string        `conf:"access_secret" env:"ACCESS_SECRET" secret:"true"`   // Секрет для подписи access токенов
+	RefreshSecret string        `conf:"refresh_secret" env:"REFRESH_SECRET" secret:"true"` // Секрет для подписи refresh токенов
+	KeysFile      string        `conf:"keys_file" env:"JWT_KEYS_FILE"`                     // Файл ключей RS256/EdDSA с расписанием ротации, без него - HS256 с AccessSecret
+	AccessTTL     time.Duration `conf:"access_ttl" env:"ACCESS_TTL"`                       // Время жизни access токена (15m)
+	RefreshTTL    time.Duration `conf:"refresh_ttl" env:"REFRESH_TTL"`                     // Время жизни refresh токена (7d)
+	ResetTTL      time.Duration `conf:"reset_ttl" env:"PASSWORD_RESET_TTL"`                // Время жизни токена сброса пароля (30m)
+	VerifyTTL     time.Duration `conf:"verify_ttl" env:"VERIFY_EMAIL_TTL"`                 // Время жизни токена подтверждения email (24h)
 }
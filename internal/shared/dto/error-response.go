@@ -0,0 +1,25 @@
+package dto
+
+// ErrorResponse - тело ответа с ошибкой, единое для всех обработчиков.
+// Code - стабильный машиночитаемый код, Message - текст для пользователя, который может меняться,
+// RequestID - идентификатор запроса, по которому ошибку можно найти в логах.
+type ErrorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id"`
+}
+
+// FieldErrors - details ответа validation_failed: по ошибке на каждое некорректное поле запроса.
+type FieldErrors struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// FieldError - ошибка поля запроса. Field - имя поля в JSON, Code - нарушенное правило,
+// Param - его параметр (например минимальная длина), Message - описание на языке запроса.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+type CreateItemRequest struct {
+	SKU         string            `json:"sku" validate:"required,max=64"`
+	Name        string            `json:"name" validate:"required,max=255"`
+	Description string            `json:"description" validate:"max=2000"`
+	Unit        string            `json:"unit" validate:"required,max=32"`
+	Category    string            `json:"category" validate:"max=100"`
+	Attributes  map[string]string `json:"attributes" validate:"max=50"`
+}
+
+// UpdateItemRequest - частичное обновление товара, nil-поля остаются без изменений.
+type UpdateItemRequest struct {
+	ID          int                `json:"id" validate:"required,gt=0"`
+	SKU         *string            `json:"sku" validate:"max=64"`
+	Name        *string            `json:"name" validate:"max=255"`
+	Description *string            `json:"description" validate:"max=2000"`
+	Unit        *string            `json:"unit" validate:"max=32"`
+	Category    *string            `json:"category" validate:"max=100"`
+	Attributes  *map[string]string `json:"attributes" validate:"max=50"`
+}
+
+// ItemFilter - параметры выборки товаров из каталога.
+type ItemFilter struct {
+	Category string `json:"category"`
+	Search   string `json:"search"` // Поиск по SKU и названию
+	Limit    int    `json:"limit"`
+	Offset   int    `json:"offset"`
+}
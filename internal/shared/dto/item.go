@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// Item - карточка товара в каталоге склада.
+type Item struct {
+	ID          int               `json:"id"`
+	SKU         string            `json:"sku" gorm:"uniqueIndex;not null"`
+	Name        string            `json:"name" gorm:"not null"`
+	Description string            `json:"description"`
+	Unit        string            `json:"unit" gorm:"not null"` // Единица измерения (шт, кг, м и т.д.)
+	Category    string            `json:"category" gorm:"index"`
+	Attributes  map[string]string `json:"attributes" gorm:"serializer:json"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
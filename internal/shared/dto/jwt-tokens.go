@@ -5,24 +5,48 @@ import (
 	"time"
 )
 
+// RefreshToken - запись о выданном refresh токене. Токены, полученные ротацией друг из друга,
+// образуют семейство (FamilyID) - одну сессию пользователя на устройстве.
 type RefreshToken struct {
-	ID         int       `json:"id"`
-	UserID     int       `json:"user_id"`
-	TokenHash  string    `json:"token_hash"`
-	DeviceInfo string    `json:"device_info"`
-	IPAddress  string    `json:"ip_address"`
-	ExpiresAt  time.Time `json:"expires_at"`
-	IsRevoked  bool      `json:"is_revoked"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	FamilyID   string     `json:"family_id"`
+	ParentID   *int       `json:"parent_id"`  // Токен, ротацией которого получен данный
+	AccessJti  string     `json:"access_jti"` // jti Access Token, выданного вместе с данным
+	TokenHash  string     `json:"token_hash"`
+	DeviceInfo string     `json:"device_info"`
+	IPAddress  string     `json:"ip_address"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	IsRevoked  bool       `json:"is_revoked"`
+	RotatedAt  *time.Time `json:"rotated_at"` // Когда токен был обменян на новый
+	MFA        bool       `json:"mfa"`        // При входе пройдена вторая ступень
+	CreatedAt  time.Time  `json:"created_at"`
 }
 
 type AccessToken struct {
 	UserID int    `json:"user_id"`
-	Jti    string `json:"jti"` // Уникальный идентификатор токена
+	Role   string `json:"role"` // Действующая роль пользователя на момент выдачи токена
+	Sid    string `json:"sid"`  // id сессии - семейства Refresh Token, вместе с которым выпущен токен
+	Jti    string `json:"jti"`  // Уникальный идентификатор токена
+	MFA    bool   `json:"mfa"`  // При входе пройдена вторая ступень
 	jwt.RegisteredClaims
+
+	// Заполняются, только когда запрос авторизован API ключом, а не Access Token.
+	APIKeyID int      `json:"-"`
+	Scopes   []string `json:"-"`
 }
 
 type TokenPair struct {
 	RefreshToken string `json:"refresh_token"`
 	AccessToken  string `json:"access_token"`
 }
+
+// Session - активная сессия пользователя на одном устройстве.
+type Session struct {
+	ID         string    `json:"id"`
+	DeviceInfo string    `json:"device_info"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // Сессия, из которой сделан запрос
+}
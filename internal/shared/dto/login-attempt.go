@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// LoginAttemptLimits - ограничения, которые проверяются при резервировании попытки входа.
+type LoginAttemptLimits struct {
+	Window        time.Duration   // Скользящее окно, в котором считаются попытки
+	MaxFailures   int             // Попыток входа в аккаунт до блокировки
+	MaxIPFailures int             // Попыток с одного IP, в любые аккаунты
+	LockoutFor    time.Duration   // Длительность блокировки аккаунта
+	Delays        []time.Duration // Delays[n] - задержка после n неудачных попыток, по значению на каждое n < MaxFailures
+}
+
+// LoginDenial - причина отказа в попытке входа.
+type LoginDenial string
+
+const (
+	LoginDeniedLocked LoginDenial = "locked" // Аккаунт заблокирован или попыток уже столько, что блокировка неизбежна
+	LoginDeniedIP     LoginDenial = "ip"     // Превышен лимит попыток с IP
+	LoginDeniedDelay  LoginDenial = "delay"  // Не выдержана прогрессивная задержка
+)
+
+// LoginAttempt - зарезервированная попытка входа или отказ в ней.
+type LoginAttempt struct {
+	ID         string        // Идентификатор попытки в счётчиках, по нему попытка снимается, если вход удался
+	ByEmail    int           // Попыток входа в аккаунт за окно, включая эту
+	Denied     LoginDenial   // Пусто - попытка зарезервирована
+	RetryAfter time.Duration // Через сколько можно повторить, если в попытке отказано
+}
@@ -0,0 +1,59 @@
+package dto
+
+import "time"
+
+// UserTOTP - секрет TOTP пользователя. Вторая ступень включена, только когда ConfirmedAt заполнено.
+type UserTOTP struct {
+	UserID      int        `json:"user_id" gorm:"primaryKey"`
+	Secret      string     `json:"-"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	LastStep    int64      `json:"-"` // Последний принятый интервал - код из него нельзя использовать повторно
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (UserTOTP) TableName() string {
+	return "user_totp"
+}
+
+// RecoveryCode - одноразовый код восстановления. В БД хранится только SHA-256 хэш.
+type RecoveryCode struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFAChallenge - ответ на вход по паролю, когда требуется вторая ступень.
+// Токен вызова обменивается на пару токенов в /VerifyMFA вместе с кодом.
+type MFAChallenge struct {
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	ExpiresIn      int    `json:"expires_in"` // Секунд до истечения токена вызова
+}
+
+// TOTPEnrollment - данные для добавления аккаунта в приложение-аутентификатор.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTOTPRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// VerifyMFARequest - второй шаг входа: код из приложения или, если к нему нет доступа, код восстановления.
+type VerifyMFARequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
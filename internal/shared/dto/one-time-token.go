@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// Назначения одноразовых токенов.
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeMFAChallenge      = "mfa_challenge"
+)
+
+// OneTimeToken - одноразовый токен для подтверждения действия по ссылке из письма или второго шага входа.
+// В БД хранится только SHA-256 хэш токена, как и у refresh токенов.
+type OneTimeToken struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	Purpose   string     `json:"purpose"`
+	TokenHash string     `json:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
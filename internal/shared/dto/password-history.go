@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// PasswordHistory - хэш прежнего пароля пользователя. Нужен, чтобы пароль нельзя было сменить на недавно использованный.
+type PasswordHistory struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Hash      string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (PasswordHistory) TableName() string {
+	return "password_history"
+}
@@ -0,0 +1,133 @@
+package dto
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// QuantityScale - знаков после запятой в количестве товара, как в столбцах NUMERIC(18,3).
+const QuantityScale = 3
+
+// quantityUnit - тысячных в единице товара.
+const quantityUnit = 1000
+
+// maxQuantityDigits - знаков в целой части NUMERIC(18,3).
+const maxQuantityDigits = 18 - QuantityScale
+
+// ErrInvalidQuantity - количество не число или в нём больше QuantityScale знаков после запятой.
+var ErrInvalidQuantity = errors.New("некорректное количество")
+
+// Quantity - количество товара в тысячных долях единицы. Хранится целым числом, чтобы суммы и сравнения
+// остатков были точными: в JSON и в БД передаётся десятичной записью без потери точности.
+type Quantity int64
+
+// ParseQuantity - разбирает десятичную запись вида 12, -3.5 или 0.125.
+// Больше QuantityScale значащих знаков после запятой - ошибка: такое количество не сохранится точно.
+func ParseQuantity(s string) (Quantity, error) {
+	invalid := fmt.Errorf("%w: %q", ErrInvalidQuantity, s)
+
+	negative := strings.HasPrefix(s, "-")
+	intPart, fracPart, _ := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	fracPart = strings.TrimRight(fracPart, "0")
+
+	if intPart == "" || len(intPart) > maxQuantityDigits || len(fracPart) > QuantityScale ||
+		!isDigits(intPart) || !isDigits(fracPart) {
+		return 0, invalid
+	}
+
+	units, _ := strconv.ParseInt(intPart, 10, 64)
+	frac, _ := strconv.ParseInt(fracPart+strings.Repeat("0", QuantityScale-len(fracPart)), 10, 64)
+
+	q := Quantity(units*quantityUnit + frac)
+	if negative {
+		q = -q
+	}
+	return q, nil
+}
+
+// String - десятичная запись без лишних нулей после запятой: 12, 3.5, 0.125.
+func (q Quantity) String() string {
+	sign := ""
+	if q < 0 {
+		sign, q = "-", -q
+	}
+
+	s := sign + strconv.FormatInt(int64(q/quantityUnit), 10)
+	if frac := int64(q % quantityUnit); frac != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%0*d", QuantityScale, frac), "0")
+	}
+	return s
+}
+
+// Abs - количество без знака.
+func (q Quantity) Abs() Quantity {
+	if q < 0 {
+		return -q
+	}
+	return q
+}
+
+// MarshalJSON - количество JSON числом.
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalJSON - разбирает JSON число, не переводя его во float64.
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	parsed, err := ParseQuantity(string(data))
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// Value - десятичная запись для столбца NUMERIC.
+func (q Quantity) Value() (driver.Value, error) {
+	return q.String(), nil
+}
+
+// Scan - читает NUMERIC, который драйвер отдаёт строкой, и целые значения.
+func (q *Quantity) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*q = 0
+	case string:
+		return q.scanString(v)
+	case []byte:
+		return q.scanString(string(v))
+	case int64:
+		*q = Quantity(v * quantityUnit)
+	case float64:
+		*q = Quantity(math.Round(v * quantityUnit))
+	default:
+		return fmt.Errorf("%w: неподдерживаемый тип %T", ErrInvalidQuantity, src)
+	}
+	return nil
+}
+
+func (q *Quantity) scanString(s string) error {
+	parsed, err := ParseQuantity(s)
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
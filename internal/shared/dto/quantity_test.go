@@ -0,0 +1,171 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Quantity
+		wantErr bool
+	}{
+		{in: "12", want: 12_000},
+		{in: "0", want: 0},
+		{in: "0.125", want: 125},
+		{in: "3.5", want: 3_500},
+		{in: "-3.5", want: -3_500},
+		{in: "-0.001", want: -1},
+		{in: "1.", want: 1_000},
+		{in: "1.2500", want: 1_250}, // Нули после значащих знаков не считаются
+		{in: "999999999999999.999", want: 999_999_999_999_999_999},
+
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: ".5", wantErr: true},
+		{in: "+1", wantErr: true},
+		{in: "--1", wantErr: true},
+		{in: " 1", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "1,5", wantErr: true},
+		{in: "1.2345", wantErr: true},               // Больше трёх знаков после запятой
+		{in: "0.0001", wantErr: true},               // Не сохранится точно
+		{in: "1000000000000000", wantErr: true},     // 16 знаков в целой части - больше, чем вмещает NUMERIC(18,3)
+		{in: "99999999999999999999", wantErr: true}, // Не помещается и в int64
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseQuantity(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidQuantity) {
+					t.Fatalf("ошибка %v, ожидалась %v", err, ErrInvalidQuantity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseQuantity: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("%d, ожидалось %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuantityString(t *testing.T) {
+	tests := []struct {
+		q    Quantity
+		want string
+	}{
+		{q: 0, want: "0"},
+		{q: 12_000, want: "12"},
+		{q: 3_500, want: "3.5"},
+		{q: 125, want: "0.125"},
+		{q: 1_010, want: "1.01"},
+		{q: -500, want: "-0.5"},
+		{q: -12_345, want: "-12.345"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.q.String(); got != tt.want {
+				t.Fatalf("%q, ожидалось %q", got, tt.want)
+			}
+
+			// Запись разбирается обратно в то же количество.
+			parsed, err := ParseQuantity(tt.want)
+			if err != nil || parsed != tt.q {
+				t.Fatalf("ParseQuantity(%q) = %d, %v", tt.want, parsed, err)
+			}
+		})
+	}
+}
+
+func TestQuantityJSON(t *testing.T) {
+	var req struct {
+		Quantity Quantity `json:"quantity"`
+	}
+
+	// Число разбирается без float64: 0.1 + 0.2 дают ровно 0.3.
+	for _, in := range []string{`{"quantity": 0.1}`, `{"quantity": 0.2}`} {
+		var part struct {
+			Quantity Quantity `json:"quantity"`
+		}
+		if err := json.Unmarshal([]byte(in), &part); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", in, err)
+		}
+		req.Quantity += part.Quantity
+	}
+	if req.Quantity != 300 {
+		t.Fatalf("%d, ожидалось 300", req.Quantity)
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"quantity":0.3}` {
+		t.Fatalf("%s", data)
+	}
+
+	// null оставляет значение как есть, строка и лишние знаки - ошибка.
+	if err := json.Unmarshal([]byte(`{"quantity": null}`), &req); err != nil || req.Quantity != 300 {
+		t.Fatalf("null: %d, %v", req.Quantity, err)
+	}
+	for _, in := range []string{`{"quantity": "1"}`, `{"quantity": 0.0001}`, `{"quantity": 1e3}`} {
+		if err := json.Unmarshal([]byte(in), &req); !errors.Is(err, ErrInvalidQuantity) {
+			t.Fatalf("Unmarshal(%s): ошибка %v, ожидалась %v", in, err, ErrInvalidQuantity)
+		}
+	}
+}
+
+func TestQuantityScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    Quantity
+		wantErr bool
+	}{
+		{name: "NULL", src: nil, want: 0},
+		{name: "NUMERIC строкой", src: "12.500", want: 12_500},
+		{name: "NUMERIC байтами", src: []byte("-0.125"), want: -125},
+		{name: "NUMERIC без дробной части", src: "7", want: 7_000},
+		{name: "целое", src: int64(3), want: 3_000},
+		{name: "float округляется до тысячных", src: 1.2345, want: 1_235},
+		{name: "лишние знаки в NUMERIC", src: "1.0001", wantErr: true},
+		{name: "не число", src: "abc", wantErr: true},
+		{name: "неподдерживаемый тип", src: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := Quantity(42)
+			err := q.Scan(tt.src)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidQuantity) {
+					t.Fatalf("ошибка %v, ожидалась %v", err, ErrInvalidQuantity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if q != tt.want {
+				t.Fatalf("%d, ожидалось %d", q, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuantityValue(t *testing.T) {
+	v, err := Quantity(-3_050).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "-3.05" {
+		t.Fatalf("%v, ожидалось -3.05", v)
+	}
+}
@@ -0,0 +1,40 @@
+package dto
+
+type MovementRequest struct {
+	Type           string   `json:"type" validate:"required,oneof=receipt issue transfer adjustment"`
+	ItemID         int      `json:"item_id" validate:"required,gt=0"`
+	FromLocationID *int     `json:"from_location_id" validate:"gt=0"`
+	ToLocationID   *int     `json:"to_location_id" validate:"gt=0"`
+	Quantity       Quantity `json:"quantity" validate:"required,gt=0"`
+	Reason         string   `json:"reason" validate:"max=500"`
+	Reference      string   `json:"reference" validate:"max=100"`
+}
+
+// MovementFilter - параметры выборки из журнала движений.
+type MovementFilter struct {
+	ItemID     int    `json:"item_id"`
+	LocationID int    `json:"location_id"` // Движения, где место хранения - источник или получатель
+	Type       string `json:"type"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+}
+
+// StockReconciliationRequest - исправление остатков по журналу движений.
+type StockReconciliationRequest struct {
+	ItemID int `json:"item_id"` // 0 - по всем товарам
+}
+
+// StockBalance - остаток товара в месте хранения.
+type StockBalance struct {
+	ItemID     int      `json:"item_id"`
+	LocationID int      `json:"location_id"`
+	Quantity   Quantity `json:"quantity"`
+}
+
+// StockDiscrepancy - расхождение между журналом движений и таблицей остатков.
+type StockDiscrepancy struct {
+	ItemID         int      `json:"item_id"`
+	LocationID     int      `json:"location_id"`
+	LedgerQuantity Quantity `json:"ledger_quantity"`
+	StockQuantity  Quantity `json:"stock_quantity"`
+}
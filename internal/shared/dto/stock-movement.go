@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// Типы складских движений.
+const (
+	MovementReceipt    = "receipt"    // Приход на склад
+	MovementIssue      = "issue"      // Расход со склада
+	MovementTransfer   = "transfer"   // Перемещение между местами хранения
+	MovementAdjustment = "adjustment" // Корректировка по результатам инвентаризации
+
+	// MovementReconciliation - исправление таблицы остатков по журналу при сверке. Не входит в остатки по журналу:
+	// фиксирует, кто и насколько изменил таблицу остатков. Через RecordMovement не проводится.
+	MovementReconciliation = "reconciliation"
+)
+
+// StockMovement - неизменяемая запись журнала движений товара.
+// Количество всегда положительное: FromLocationID уменьшает остаток, ToLocationID увеличивает.
+type StockMovement struct {
+	ID             int       `json:"id"`
+	Type           string    `json:"type" gorm:"not null"`
+	ItemID         int       `json:"item_id" gorm:"index;not null"`
+	FromLocationID *int      `json:"from_location_id,omitempty" gorm:"index"`
+	ToLocationID   *int      `json:"to_location_id,omitempty" gorm:"index"`
+	Quantity       Quantity  `json:"quantity" gorm:"type:numeric(18,3);not null"`
+	Reason         string    `json:"reason"`
+	Reference      string    `json:"reference"` // Номер документа-основания (накладная, акт и т.д.)
+	UserID         int       `json:"user_id" gorm:"index;not null"`
+	CreatedAt      time.Time `json:"created_at"`
+}
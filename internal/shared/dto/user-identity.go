@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// UserIdentity - привязка внешнего аккаунта провайдера OpenID Connect к пользователю.
+// Аккаунт провайдера определяется парой issuer и subject: email у провайдера может смениться, subject - нет.
+type UserIdentity struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	Issuer      string     `json:"issuer"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// OIDCState - данные начатого входа через провайдера, хранятся до возврата пользователя в OIDCCallback.
+type OIDCState struct {
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
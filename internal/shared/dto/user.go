@@ -3,12 +3,14 @@ package dto
 import "time"
 
 type User struct {
-	ID        int       `json:"id"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	IsAdmin   bool      `json:"is_admin"`
-	Email     string    `json:"email"`
-	Hash      string    `json:"hash"`
-	UpdatedAt time.Time `json:"updated_at"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            int       `json:"id"`
+	FirstName     string    `json:"first_name"`
+	LastName      string    `json:"last_name"`
+	IsAdmin       bool      `json:"is_admin"`
+	Role          string    `json:"role" gorm:"not null;default:read_only"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified" gorm:"not null;default:false"`
+	Hash          string    `json:"hash"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	CreatedAt     time.Time `json:"created_at"`
 }
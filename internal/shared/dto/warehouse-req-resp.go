@@ -0,0 +1,29 @@
+package dto
+
+type CreateWarehouseRequest struct {
+	Code    string `json:"code" validate:"required,max=32"`
+	Name    string `json:"name" validate:"required,max=255"`
+	Address string `json:"address" validate:"max=500"`
+}
+
+type CreateLocationRequest struct {
+	WarehouseID int    `json:"warehouse_id" validate:"required,gt=0"`
+	Code        string `json:"code" validate:"required,max=32"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+// LocationStock - остаток товара в одном месте хранения.
+type LocationStock struct {
+	LocationID   int      `json:"location_id"`
+	LocationCode string   `json:"location_code"`
+	WarehouseID  int      `json:"warehouse_id"`
+	Quantity     Quantity `json:"quantity"`
+}
+
+// ItemStock - сводный остаток товара по всем складам или по одному складу.
+type ItemStock struct {
+	ItemID      int             `json:"item_id"`
+	WarehouseID int             `json:"warehouse_id,omitempty"` // 0 - по всем складам
+	OnHand      Quantity        `json:"on_hand"`
+	Locations   []LocationStock `json:"locations"`
+}
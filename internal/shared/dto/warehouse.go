@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// Warehouse - складская площадка.
+type Warehouse struct {
+	ID        int       `json:"id"`
+	Code      string    `json:"code" gorm:"uniqueIndex;not null"`
+	Name      string    `json:"name" gorm:"not null"`
+	Address   string    `json:"address"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Location - место хранения внутри склада (ячейка, полка, стеллаж).
+type Location struct {
+	ID          int       `json:"id"`
+	WarehouseID int       `json:"warehouse_id" gorm:"uniqueIndex:idx_location_warehouse_code;not null"`
+	Code        string    `json:"code" gorm:"uniqueIndex:idx_location_warehouse_code;not null"`
+	Description string    `json:"description"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StockLevel - остаток товара в конкретном месте хранения.
+type StockLevel struct {
+	ID         int       `json:"id"`
+	ItemID     int       `json:"item_id" gorm:"uniqueIndex:idx_stock_item_location;not null"`
+	LocationID int       `json:"location_id" gorm:"uniqueIndex:idx_stock_item_location;index;not null"`
+	Quantity   Quantity  `json:"quantity" gorm:"type:numeric(18,3);not null;default:0"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Типы событий безопасности.
+const (
+	RefreshTokenReuse = "refresh_token_reuse" // Предъявлен уже ротированный refresh token
+	AccountLocked     = "account_locked"      // Вход в аккаунт заблокирован после серии неудачных попыток
+)
+
+// SecurityEvent - событие, требующее внимания службы безопасности.
+type SecurityEvent struct {
+	Type      string
+	UserID    int
+	IPAddress string
+	Details   map[string]any
+	At        time.Time
+}
+
+// Emitter - получатель событий безопасности (журнал, SIEM, оповещения и т.д.).
+type Emitter interface {
+	Emit(ctx context.Context, event SecurityEvent)
+}
+
+// LogEmitter - пишет события безопасности в журнал приложения с уровнем Warn.
+type LogEmitter struct{}
+
+func NewLogEmitter() *LogEmitter {
+	return &LogEmitter{}
+}
+
+func (LogEmitter) Emit(ctx context.Context, event SecurityEvent) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	attrs := []any{
+		slog.String("event", event.Type),
+		slog.Int("user_id", event.UserID),
+		slog.String("ip_address", event.IPAddress),
+		slog.Time("at", event.At),
+	}
+	for k, v := range event.Details {
+		attrs = append(attrs, slog.Any(k, v))
+	}
+
+	slog.WarnContext(ctx, "Событие безопасности", attrs...)
+}
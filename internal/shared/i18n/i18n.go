@@ -0,0 +1,128 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang - язык сообщений для клиента, двухбуквенный код ISO 639-1.
+type Lang string
+
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+)
+
+// Default - язык, на котором отвечаем, если клиент не указал поддерживаемый.
+const Default = RU
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// catalogs - сообщения по кодам для каждого поддерживаемого языка. Заполняется при старте из locales.
+var catalogs = map[Lang]map[string]string{}
+
+func init() {
+	for _, lang := range []Lang{RU, EN} {
+		data, err := localesFS.ReadFile("locales/" + string(lang) + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: каталог %s не найден: %v", lang, err))
+		}
+
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: некорректный каталог %s: %v", lang, err))
+		}
+		catalogs[lang] = messages
+	}
+}
+
+// Supported - поддерживается ли язык.
+func Supported(lang Lang) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Message - сообщение с кодом code на языке lang. Если перевода нет - на языке по умолчанию,
+// если нет и его - fallback, обычно текст сентинела ошибки.
+func Message(lang Lang, code, fallback string) string {
+	if message, ok := catalogs[lang][code]; ok {
+		return message
+	}
+	if message, ok := catalogs[Default][code]; ok {
+		return message
+	}
+	return fallback
+}
+
+// languageRange - элемент заголовка Accept-Language.
+type languageRange struct {
+	tag string
+	q   float64
+}
+
+// Negotiate - выбирает язык ответа по заголовку Accept-Language (RFC 9110, раздел 12.5.4).
+// Диапазоны сравниваются по основному языку: en-US и en-GB дают EN. Диапазоны с q=0 исключаются,
+// "*" и пустой заголовок дают язык по умолчанию.
+func Negotiate(acceptLanguage string) Lang {
+	var ranges []languageRange
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.ToLower(strings.TrimSpace(name)) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			q = parsed
+		}
+		if q == 0 {
+			continue
+		}
+		ranges = append(ranges, languageRange{tag: tag, q: q})
+	}
+
+	// При равном q порядок в заголовке сохраняется: клиент перечисляет языки по убыванию предпочтения.
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	for _, r := range ranges {
+		if r.tag == "*" {
+			return Default
+		}
+		base, _, _ := strings.Cut(r.tag, "-")
+		if lang := Lang(base); Supported(lang) {
+			return lang
+		}
+	}
+	return Default
+}
+
+// langKey - неэкспортируемый тип ключа, чтобы не пересекаться с ключами других пакетов.
+type langKey struct{}
+
+// ContextWithLang - кладёт язык ответа в контекст.
+func ContextWithLang(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, langKey{}, lang)
+}
+
+// LangFromContext - возвращает язык, выбранный middleware Locale, или язык по умолчанию.
+func LangFromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(langKey{}).(Lang); ok {
+		return lang
+	}
+	return Default
+}
@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           Lang
+	}{
+		{name: "пустой заголовок", acceptLanguage: "", want: Default},
+		{name: "один язык", acceptLanguage: "en", want: EN},
+		{name: "регион и регистр не важны", acceptLanguage: "EN-gb", want: EN},
+		{name: "первый без q важнее", acceptLanguage: "en-US,ru;q=0.5", want: EN},
+		{name: "порядок по q, а не по позиции", acceptLanguage: "ru;q=0.4, en;q=0.8", want: EN},
+		{name: "при равном q - порядок в заголовке", acceptLanguage: "en;q=0.5,ru;q=0.5", want: EN},
+		{name: "неподдерживаемый пропускается", acceptLanguage: "de-DE,de;q=0.9,en;q=0.1", want: EN},
+		{name: "только неподдерживаемые", acceptLanguage: "de, fr;q=0.8", want: Default},
+		{name: "q=0 исключает язык", acceptLanguage: "en;q=0", want: Default},
+		{name: "q=0 исключает и при других языках", acceptLanguage: "en;q=0,ru;q=0.1", want: RU},
+		{name: "некорректный q считается нулём", acceptLanguage: "en;q=abc,ru;q=0.1", want: RU},
+		{name: "q больше 1 считается нулём", acceptLanguage: "en;q=2,ru;q=0.1", want: RU},
+		{name: "звёздочка - язык по умолчанию", acceptLanguage: "*", want: Default},
+		{name: "звёздочка с меньшим q", acceptLanguage: "de,*;q=0.5,en;q=0.1", want: Default},
+		{name: "пробелы и пустые элементы", acceptLanguage: " , en ; q = 0.7 ,", want: EN},
+		{name: "посторонние параметры", acceptLanguage: "en;level=1;q=0.9", want: EN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Negotiate(tt.acceptLanguage); got != tt.want {
+				t.Fatalf("Negotiate(%q) = %s, ожидалось %s", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	en, ok := catalogs[EN]["item_in_use"]
+	if !ok {
+		t.Fatal("в каталоге en нет item_in_use")
+	}
+	if got := Message(EN, "item_in_use", "fallback"); got != en {
+		t.Fatalf("сообщение %q, ожидалось %q", got, en)
+	}
+	if got := Message(Lang("de"), "item_in_use", "fallback"); got != catalogs[Default]["item_in_use"] {
+		t.Fatalf("для неподдерживаемого языка сообщение %q, ожидалось на языке по умолчанию", got)
+	}
+	if got := Message(EN, "no_such_code", "fallback"); got != "fallback" {
+		t.Fatalf("для неизвестного кода сообщение %q, ожидалось fallback", got)
+	}
+}
+
+// TestCatalogsComplete - переводы есть для всех кодов: иначе клиент получит ответ на смеси языков.
+func TestCatalogsComplete(t *testing.T) {
+	for lang, messages := range catalogs {
+		for other, otherMessages := range catalogs {
+			for code := range otherMessages {
+				if _, ok := messages[code]; !ok {
+					t.Errorf("в каталоге %s нет кода %s из каталога %s", lang, code, other)
+				}
+			}
+		}
+	}
+}
+
+func TestLangFromContext(t *testing.T) {
+	if got := LangFromContext(context.Background()); got != Default {
+		t.Fatalf("язык %s, ожидался %s", got, Default)
+	}
+	if got := LangFromContext(ContextWithLang(context.Background(), EN)); got != EN {
+		t.Fatalf("язык %s, ожидался %s", got, EN)
+	}
+}
@@ -0,0 +1,39 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// rsaBits - размер генерируемых RSA ключей.
+const rsaBits = 3072
+
+// Generate - создаёт новый приватный ключ для алгоритма alg и возвращает его в PEM (PKCS#8).
+func Generate(alg string) ([]byte, error) {
+	var private crypto.PrivateKey
+	var err error
+
+	switch alg {
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaBits)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый алгоритм %s, ожидается %s или %s", alg, AlgRS256, AlgEdDSA)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
@@ -0,0 +1,66 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"time"
+)
+
+// JWK - публичный ключ в формате JSON Web Key (RFC 7517).
+type JWK struct {
+	KTY string `json:"kty"`
+	KID string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`   // RSA: модуль
+	E   string `json:"e,omitempty"`   // RSA: экспонента
+	Crv string `json:"crv,omitempty"` // OKP: кривая
+	X   string `json:"x,omitempty"`   // OKP: публичный ключ
+}
+
+// JWKS - набор публичных ключей, которым другие сервисы проверяют Access токены без обращения к нам.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS - публикует асимметричные ключи, которыми можно проверять подпись в момент now,
+// в том числе ещё не начавшие подписывать: потребители успевают получить их заранее.
+// HS256 ключи не публикуются - это общий секрет.
+func (ks *KeySet) JWKS(now time.Time) JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+
+	for _, key := range ks.keys {
+		if key.retired(now) {
+			continue
+		}
+
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KTY: "RSA",
+				KID: key.KID,
+				Alg: key.Alg,
+				Use: "sig",
+				N:   encode(pub.N.Bytes()),
+				E:   encode(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KTY: "OKP",
+				KID: key.KID,
+				Alg: key.Alg,
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   encode(pub),
+			})
+		}
+	}
+
+	return set
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
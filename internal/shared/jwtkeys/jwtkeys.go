@@ -0,0 +1,239 @@
+package jwtkeys
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Поддерживаемые алгоритмы подписи.
+const (
+	AlgHS256 = "HS256" // Общий секрет, ключ не публикуется в JWKS
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// DefaultKID - идентификатор HS256 ключа из ACCESS_SECRET, используемого без файла ключей.
+// Им же проверяются токены, выпущенные до появления kid в заголовке.
+const DefaultKID = "default"
+
+// Errors:
+var (
+	ErrUnknownKey    = errors.New("ключ подписи с указанным kid не найден")
+	ErrNoSigningKey  = errors.New("нет ни одного действующего ключа подписи")
+	ErrAlgMismatch   = errors.New("алгоритм токена не совпадает с алгоритмом ключа")
+	ErrKeyTypeAndAlg = errors.New("тип ключа не соответствует алгоритму")
+)
+
+// Key - ключ подписи Access токенов.
+// С ActiveFrom ключ начинает подписывать новые токены, до RetireAt им можно проверять подпись.
+type Key struct {
+	KID        string
+	Alg        string
+	ActiveFrom time.Time
+	RetireAt   *time.Time
+	private    crypto.PrivateKey // *rsa.PrivateKey, ed25519.PrivateKey или []byte для HS256
+	public     crypto.PublicKey
+}
+
+// Method - метод подписи golang-jwt для алгоритма ключа.
+func (k *Key) Method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Alg)
+}
+
+// Sign - подписывает claims ключом, указывая kid в заголовке токена.
+func (k *Key) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.Method(), claims)
+	token.Header["kid"] = k.KID
+	return token.SignedString(k.private)
+}
+
+// verifyKey - ключ для проверки подписи: публичный для асимметричных алгоритмов, секрет для HS256.
+func (k *Key) verifyKey() interface{} {
+	if k.Alg == AlgHS256 {
+		return k.private
+	}
+	return k.public
+}
+
+// retired - истёк ли срок, в течение которого ключом можно проверять подпись.
+func (k *Key) retired(now time.Time) bool {
+	return k.RetireAt != nil && !now.Before(*k.RetireAt)
+}
+
+// KeySet - набор ключей подписи. Подписывает самый новый из уже активных ключей,
+// проверяет подпись любой не выведенный из оборота ключ - так новый ключ публикуется заранее,
+// а старый продолжает принимать уже выданные токены до конца перекрытия.
+type KeySet struct {
+	keys []*Key // Отсортированы по ActiveFrom
+}
+
+// NewHMAC - набор из одного HS256 ключа с общим секретом.
+func NewHMAC(secret string) (*KeySet, error) {
+	if secret == "" {
+		return nil, errors.New("не задан секрет для подписи access токенов")
+	}
+
+	return &KeySet{keys: []*Key{{
+		KID:     DefaultKID,
+		Alg:     AlgHS256,
+		private: []byte(secret),
+	}}}, nil
+}
+
+// Signing - возвращает ключ, которым нужно подписывать токены в момент now.
+func (ks *KeySet) Signing(now time.Time) (*Key, error) {
+	for i := len(ks.keys) - 1; i >= 0; i-- {
+		key := ks.keys[i]
+		if !key.ActiveFrom.After(now) && !key.retired(now) {
+			return key, nil
+		}
+	}
+	return nil, ErrNoSigningKey
+}
+
+// Lookup - возвращает ключ для проверки подписи по kid. Пустой kid означает ключ DefaultKID.
+func (ks *KeySet) Lookup(kid string, now time.Time) (*Key, error) {
+	if kid == "" {
+		kid = DefaultKID
+	}
+
+	for _, key := range ks.keys {
+		if key.KID == kid && !key.retired(now) {
+			return key, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+// Keyfunc - функция выбора ключа для jwt.Parse: ключ ищется по kid, его алгоритм должен совпадать с алгоритмом токена.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, err := ks.Lookup(kid, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.Alg {
+		return nil, ErrAlgMismatch
+	}
+
+	return key.verifyKey(), nil
+}
+
+// manifest - файл с описанием ключей: пути к приватным ключам в PEM и расписание их ротации.
+type manifest struct {
+	Keys []struct {
+		KID        string     `json:"kid"`
+		Alg        string     `json:"alg"`
+		PrivateKey string     `json:"private_key"` // Путь к PEM файлу, относительный - от файла манифеста
+		ActiveFrom time.Time  `json:"active_from"`
+		RetireAt   *time.Time `json:"retire_at,omitempty"`
+	} `json:"keys"`
+}
+
+// Load - читает набор ключей из JSON манифеста.
+func Load(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("разбор файла ключей %s: %w", path, err)
+	}
+
+	ks := &KeySet{}
+	seen := make(map[string]struct{}, len(m.Keys))
+	for _, entry := range m.Keys {
+		if entry.KID == "" {
+			return nil, errors.New("у ключа в файле ключей не указан kid")
+		}
+		if _, ok := seen[entry.KID]; ok {
+			return nil, fmt.Errorf("kid %s указан в файле ключей дважды", entry.KID)
+		}
+		seen[entry.KID] = struct{}{}
+
+		keyPath := entry.PrivateKey
+		if !filepath.IsAbs(keyPath) {
+			keyPath = filepath.Join(filepath.Dir(path), keyPath)
+		}
+
+		key, err := loadKey(keyPath, entry.Alg)
+		if err != nil {
+			return nil, fmt.Errorf("ключ %s: %w", entry.KID, err)
+		}
+		key.KID = entry.KID
+		key.ActiveFrom = entry.ActiveFrom
+		key.RetireAt = entry.RetireAt
+
+		ks.keys = append(ks.keys, key)
+	}
+	sort.SliceStable(ks.keys, func(i, j int) bool { return ks.keys[i].ActiveFrom.Before(ks.keys[j].ActiveFrom) })
+
+	// Набор, которым прямо сейчас нечего подписывать, - ошибка конфигурации.
+	if _, err := ks.Signing(time.Now()); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// loadKey - читает приватный ключ PKCS#8 (или PKCS#1 для RSA) из PEM файла и проверяет, что он подходит алгоритму.
+func loadKey(path, alg string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("в файле %s нет PEM блока", path)
+	}
+
+	var private crypto.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		if alg != AlgRS256 {
+			return nil, ErrKeyTypeAndAlg
+		}
+		return &Key{Alg: alg, private: k, public: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		if alg != AlgEdDSA {
+			return nil, ErrKeyTypeAndAlg
+		}
+		return &Key{Alg: alg, private: k, public: k.Public()}, nil
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип ключа %T", private)
+	}
+}
+
+// New - создаёт набор ключей по конфигурации: из файла ключей, если он задан, иначе HS256 ключ из ACCESS_SECRET.
+func New(cfg *config.TokenConfig) (*KeySet, error) {
+	if cfg.KeysFile != "" {
+		return Load(cfg.KeysFile)
+	}
+	return NewHMAC(cfg.AccessSecret)
+}
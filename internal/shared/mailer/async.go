@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// AsyncMailer - отправляет письма через next в фоне: Send возвращается сразу, ошибки доставки только логируются.
+// Время и статус ответа не зависят от отправки письма, поэтому по ним нельзя узнать, существует ли адрес.
+type AsyncMailer struct {
+	next Mailer
+	wg   sync.WaitGroup
+}
+
+func NewAsyncMailer(next Mailer) *AsyncMailer {
+	return &AsyncMailer{next: next}
+}
+
+func (a *AsyncMailer) Send(ctx context.Context, msg Message) error {
+	// Запрос завершится раньше, чем уйдёт письмо: его отмена не должна прерывать отправку.
+	ctx = context.WithoutCancel(ctx)
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		if err := a.next.Send(ctx, msg); err != nil {
+			slog.Error("Не удалось отправить письмо", "to", msg.To, "subject", msg.Subject, "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Wait - дожидается отправки начатых писем. Возвращает ошибку ctx, если он завершился раньше.
+func (a *AsyncMailer) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"context"
+	"fmt"
+)
+
+// Message - письмо пользователю.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer - способ доставки писем. Реализации: SMTP для боевого окружения, файлы и память для локальной разработки.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New - создаёт Mailer по драйверу из конфигурации. Драйвер обязателен: письма не должны незаметно
+// оставаться в памяти процесса из-за того, что драйвер забыли указать.
+func New(cfg *config.MailerConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case config.MailerDriverSMTP:
+		return NewSMTPMailer(cfg.SMTPAddr, cfg.SMTPUser, cfg.SMTPPass, cfg.From), nil
+	case config.MailerDriverFile:
+		return NewFileMailer(cfg.Dir, cfg.From)
+	case config.MailerDriverMemory:
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер почты: %s", cfg.Driver)
+	}
+}
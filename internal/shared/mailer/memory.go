@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// MemoryMailer - хранит отправленные письма в памяти процесса.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(_ context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+	slog.Info("Письмо сохранено в памяти", "to", msg.To, "subject", msg.Subject)
+
+	return nil
+}
+
+// Messages - возвращает копию списка отправленных писем.
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message(nil), m.messages...)
+}
+
+// Last - возвращает последнее письмо, отправленное на адрес to.
+func (m *MemoryMailer) Last(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+
+	return Message{}, false
+}
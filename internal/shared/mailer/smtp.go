@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+)
+
+// SMTPMailer - отправляет письма через SMTP сервер.
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(addr, user, pass, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if user != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", user, pass, host)
+	}
+
+	return &SMTPMailer{addr: addr, auth: auth, from: from}
+}
+
+func (s *SMTPMailer) Send(_ context.Context, msg Message) error {
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, []byte(format(s.from, msg)))
+}
@@ -0,0 +1,310 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockID - ключ advisory lock, не дающий двум процессам мигрировать базу одновременно.
+const lockID = 7239163508
+
+// unlockTimeout - сколько ждём снятия блокировки миграций, когда контекст вызывающего уже отменён.
+const unlockTimeout = 5 * time.Second
+
+// fileRegex - формат имени файла миграции: 0001_name.up.sql / 0001_name.down.sql.
+var fileRegex = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Errors:
+var (
+	ErrUnknownVersion = errors.New("миграция с указанной версией не найдена")
+	ErrSchemaOutdated = errors.New("схема БД не актуальна, выполните migrate up")
+)
+
+// Migration - пара SQL скриптов, переводящих схему на версию вперёд и обратно.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status - состояние одной миграции в БД.
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// schemaMigration - строка таблицы schema_migrations.
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New - создаёт Migrator с миграциями, встроенными в бинарник.
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest - возвращает номер последней известной миграции.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status - возвращает список всех миграций с отметкой о применении.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		status := Status{Version: mig.Version, Name: mig.Name}
+		if row, ok := applied[mig.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Check - возвращает ErrSchemaOutdated, если в БД применены не все миграции.
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			return ErrSchemaOutdated
+		}
+	}
+
+	return nil
+}
+
+// Up - применяет все непримененные миграции.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down - откатывает последнюю применённую миграцию.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				return m.rollback(ctx, conn, m.migrations[i])
+			}
+		}
+
+		slog.Info("Нет применённых миграций для отката")
+		return nil
+	})
+}
+
+// To - приводит схему к версии version: применяет недостающие миграции до неё
+// и откатывает применённые после неё. Версия 0 откатывает все миграции.
+func (m *Migrator) To(ctx context.Context, version int) error {
+	if version != 0 && !m.known(version) {
+		return ErrUnknownVersion
+	}
+
+	return m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		// Откатываем миграции новее целевой версии, начиная с последней.
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; ok && mig.Version > version {
+				if err := m.rollback(ctx, conn, mig); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Применяем недостающие миграции до целевой версии включительно.
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+				if err := m.apply(ctx, conn, mig); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// apply - выполняет up скрипт и отмечает миграцию применённой в одной транзакции.
+func (m *Migrator) apply(ctx context.Context, conn *gorm.DB, mig Migration) error {
+	err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(mig.Up).Error; err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("миграция %04d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	slog.Info("Миграция применена", "version", mig.Version, "name", mig.Name)
+	return nil
+}
+
+// rollback - выполняет down скрипт и удаляет отметку о миграции в одной транзакции.
+func (m *Migrator) rollback(ctx context.Context, conn *gorm.DB, mig Migration) error {
+	err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(mig.Down).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&schemaMigration{}, "version = ?", mig.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("откат миграции %04d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	slog.Info("Миграция откачена", "version", mig.Version, "name", mig.Name)
+	return nil
+}
+
+// applied - возвращает применённые миграции, создавая таблицу schema_migrations при необходимости.
+func (m *Migrator) applied(ctx context.Context, conn *gorm.DB) (map[int]schemaMigration, error) {
+	if err := conn.WithContext(ctx).Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT PRIMARY KEY,
+		name       TEXT        NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []schemaMigration
+	if err := conn.WithContext(ctx).Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+// withLock - выполняет fn на выделенном соединении под advisory lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", lockID).Error; err != nil {
+			return err
+		}
+		defer func() {
+			// Блокировка принадлежит сессии и осталась бы на соединении в пуле, поэтому снимаем её
+			// и после отмены ctx (Ctrl+C, таймаут): отменённый контекст не дал бы выполнить запрос.
+			unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), unlockTimeout)
+			defer cancel()
+			if err := conn.WithContext(unlockCtx).Exec("SELECT pg_advisory_unlock(?)", lockID).Error; err != nil {
+				slog.Error("Не удалось снять блокировку миграций", "error", err)
+			}
+		}()
+
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) known(version int) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// load - читает SQL файлы и собирает их в отсортированный по версии список миграций.
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("некорректное имя файла миграции: %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, "sql/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != match[2] {
+			return nil, fmt.Errorf("у миграции %04d разные имена: %s и %s", version, mig.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			mig.Up = string(content)
+		} else {
+			mig.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("у миграции %04d_%s должны быть up и down скрипты", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
@@ -0,0 +1,13 @@
+-- IF NOT EXISTS - чтобы принять под управление базы, созданные ранее через AutoMigrate.
+CREATE TABLE IF NOT EXISTS users (
+    id         BIGSERIAL PRIMARY KEY,
+    first_name TEXT,
+    last_name  TEXT,
+    is_admin   BOOLEAN     NOT NULL DEFAULT FALSE,
+    email      TEXT        NOT NULL,
+    hash       TEXT        NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
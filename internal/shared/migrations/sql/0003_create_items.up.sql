@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS items (
+    id          BIGSERIAL PRIMARY KEY,
+    sku         TEXT        NOT NULL,
+    name        TEXT        NOT NULL,
+    description TEXT,
+    unit        TEXT        NOT NULL,
+    category    TEXT,
+    attributes  JSONB,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_items_sku ON items (sku);
+CREATE INDEX IF NOT EXISTS idx_items_category ON items (category);
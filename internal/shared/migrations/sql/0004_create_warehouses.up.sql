@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS warehouses (
+    id         BIGSERIAL PRIMARY KEY,
+    code       TEXT        NOT NULL,
+    name       TEXT        NOT NULL,
+    address    TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_code ON warehouses (code);
+
+CREATE TABLE IF NOT EXISTS locations (
+    id           BIGSERIAL PRIMARY KEY,
+    warehouse_id BIGINT      NOT NULL REFERENCES warehouses (id),
+    code         TEXT        NOT NULL,
+    description  TEXT,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_location_warehouse_code ON locations (warehouse_id, code);
+
+CREATE TABLE IF NOT EXISTS stock_levels (
+    id          BIGSERIAL PRIMARY KEY,
+    item_id     BIGINT         NOT NULL REFERENCES items (id),
+    location_id BIGINT         NOT NULL REFERENCES locations (id),
+    quantity    NUMERIC(18, 3) NOT NULL DEFAULT 0,
+    updated_at  TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_item_location ON stock_levels (item_id, location_id);
+CREATE INDEX IF NOT EXISTS idx_stock_levels_location_id ON stock_levels (location_id);
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id               BIGSERIAL PRIMARY KEY,
+    type             TEXT           NOT NULL CHECK (type IN ('receipt', 'issue', 'transfer', 'adjustment')),
+    item_id          BIGINT         NOT NULL REFERENCES items (id),
+    from_location_id BIGINT REFERENCES locations (id),
+    to_location_id   BIGINT REFERENCES locations (id),
+    quantity         NUMERIC(18, 3) NOT NULL CHECK (quantity > 0),
+    reason           TEXT,
+    reference        TEXT,
+    user_id          BIGINT         NOT NULL REFERENCES users (id),
+    created_at       TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_item_id ON stock_movements (item_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_from_location_id ON stock_movements (from_location_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_to_location_id ON stock_movements (to_location_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_user_id ON stock_movements (user_id);
+
+-- Журнал движений неизменяем: исправления оформляются новыми движениями.
+CREATE OR REPLACE FUNCTION stock_movements_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_stock_movements_immutable ON stock_movements;
+CREATE TRIGGER trg_stock_movements_immutable
+    BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_immutable();
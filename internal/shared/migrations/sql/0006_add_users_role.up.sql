@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'read_only';
+
+-- Существующие администраторы получают соответствующую роль.
+UPDATE users SET role = 'admin' WHERE is_admin = TRUE;
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Пользователи, зарегистрированные до появления подтверждения, считаются подтверждёнными.
+UPDATE users SET email_verified = TRUE;
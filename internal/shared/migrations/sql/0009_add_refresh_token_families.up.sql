@@ -0,0 +1,12 @@
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS family_id  TEXT,
+    ADD COLUMN IF NOT EXISTS parent_id  BIGINT REFERENCES refresh_tokens (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS access_jti TEXT,
+    ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMPTZ;
+
+-- Каждый ранее выданный токен становится отдельным семейством.
+UPDATE refresh_tokens SET family_id = gen_random_uuid()::TEXT WHERE family_id IS NULL;
+
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id      BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret       TEXT        NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    last_step    BIGINT      NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  TEXT        NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id) WHERE used_at IS NULL;
+
+-- Пройдена ли при входе вторая ступень. Наследуется при ротации, чтобы сессия не теряла отметку.
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS mfa BOOLEAN NOT NULL DEFAULT FALSE;
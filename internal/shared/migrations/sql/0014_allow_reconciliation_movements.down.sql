@@ -0,0 +1,5 @@
+-- Журнал неизменяем, поэтому уже записанные исправления остаются: ограничение проверяет только новые строки.
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_type_check;
+ALTER TABLE stock_movements
+    ADD CONSTRAINT stock_movements_type_check
+        CHECK (type IN ('receipt', 'issue', 'transfer', 'adjustment')) NOT VALID;
@@ -0,0 +1,5 @@
+-- Исправления остатков по сверке записываются в журнал отдельным типом движения.
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_type_check;
+ALTER TABLE stock_movements
+    ADD CONSTRAINT stock_movements_type_check
+        CHECK (type IN ('receipt', 'issue', 'transfer', 'adjustment', 'reconciliation'));
@@ -0,0 +1,195 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksRefreshInterval - ключи провайдера перечитываются при встрече неизвестного kid, но не чаще этого интервала:
+// иначе поток токенов с выдуманными kid превратился бы в поток запросов к провайдеру.
+const jwksRefreshInterval = time.Minute
+
+// jwk - ключ провайдера в формате JSON Web Key (RFC 7517).
+type jwk struct {
+	KTY string `json:"kty"`
+	KID string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type publicKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// remoteKeySet - кэш ключей подписи провайдера, загружаемых по jwks_uri.
+type remoteKeySet struct {
+	uri    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      []publicKey
+	fetchedAt time.Time
+}
+
+func newRemoteKeySet(uri string, client *http.Client) *remoteKeySet {
+	return &remoteKeySet{uri: uri, client: client}
+}
+
+// key - возвращает ключ для проверки подписи по kid. Если ключа нет - провайдер мог его ротировать,
+// тогда набор перечитывается. Токен без kid принимается, только если у провайдера один ключ.
+func (s *remoteKeySet) key(ctx context.Context, kid, alg string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.find(kid); ok {
+		return checkAlg(key, alg)
+	}
+
+	if time.Since(s.fetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("ключ подписи %q не найден", kid)
+	}
+	if err := s.fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	if key, ok := s.find(kid); ok {
+		return checkAlg(key, alg)
+	}
+	return nil, fmt.Errorf("ключ подписи %q не найден", kid)
+}
+
+func (s *remoteKeySet) find(kid string) (publicKey, bool) {
+	if kid == "" {
+		if len(s.keys) == 1 {
+			return s.keys[0], true
+		}
+		return publicKey{}, false
+	}
+
+	for _, key := range s.keys {
+		if key.kid == kid {
+			return key, true
+		}
+	}
+	return publicKey{}, false
+}
+
+// fetch - загружает набор ключей. Ключи шифрования и неподдерживаемых типов пропускаются.
+func (s *remoteKeySet) fetch(ctx context.Context) error {
+	s.fetchedAt = time.Now()
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := getJSON(ctx, s.client, s.uri, &set); err != nil {
+		return fmt.Errorf("загрузка ключей провайдера: %w", err)
+	}
+
+	keys := make([]publicKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys = append(keys, publicKey{kid: k.KID, alg: k.Alg, key: key})
+	}
+
+	s.keys = keys
+	return nil
+}
+
+// checkAlg - ключ, для которого провайдер указал алгоритм, принимает подписи только этим алгоритмом.
+// Тип ключа и алгоритм токена дополнительно сверяет golang-jwt.
+func checkAlg(key publicKey, alg string) (crypto.PublicKey, error) {
+	if key.alg != "" && key.alg != alg {
+		return nil, fmt.Errorf("алгоритм токена %s не совпадает с алгоритмом ключа %s", alg, key.alg)
+	}
+	return key.key, nil
+}
+
+// publicKey - разбирает JWK в публичный ключ.
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.KTY {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("некорректная экспонента RSA ключа")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("неподдерживаемая кривая %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("точка EC ключа не лежит на кривой")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("неподдерживаемая кривая %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("некорректный Ed25519 ключ")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип ключа %s", k.KTY)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("некорректное число в JWK")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,265 @@
+package oidc
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors:
+var (
+	ErrDiscovery       = errors.New("не удалось получить discovery документ провайдера")
+	ErrExchange        = errors.New("провайдер не обменял код авторизации на токены")
+	ErrInvalidIDToken  = errors.New("ID токен провайдера недействителен")
+	ErrPKCEUnsupported = errors.New("провайдер не поддерживает PKCE S256")
+)
+
+// idTokenAlgs - алгоритмы подписи ID токена, которые мы принимаем. none и HMAC не принимаются:
+// клиентский секрет известен не только провайдеру.
+var idTokenAlgs = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// clockSkew - допустимое расхождение часов с провайдером при проверке exp и iat.
+const clockSkew = time.Minute
+
+// Metadata - нужная нам часть discovery документа провайдера (OpenID Connect Discovery 1.0).
+type Metadata struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+// Claims - claims ID токена, по которым находится или создаётся пользователь.
+type Claims struct {
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	GivenName     string   `json:"given_name"`
+	FamilyName    string   `json:"family_name"`
+	Nonce         string   `json:"nonce"`
+	AZP           string   `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// Provider - клиент (relying party) провайдера OpenID Connect: поток authorization code с PKCE.
+// Discovery документ читается при первом обращении и кэшируется, ключи подписи - по мере необходимости.
+type Provider struct {
+	cfg    *config.OIDCConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *Metadata
+	keys     *remoteKeySet
+}
+
+// New - создаёт клиент провайдера по конфигурации. Если провайдер не задан - возвращает nil: вход через OIDC выключен.
+func New(cfg *config.OIDCConfig) *Provider {
+	if cfg.Issuer == "" {
+		return nil
+	}
+
+	return &Provider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.HTTPTimeout},
+	}
+}
+
+// Issuer - идентификатор провайдера, вместе с subject однозначно определяет внешний аккаунт.
+func (p *Provider) Issuer() string {
+	return p.cfg.Issuer
+}
+
+// AuthCodeURL - адрес страницы входа провайдера. state возвращается провайдером в OIDCCallback и защищает от CSRF,
+// nonce - попадает в ID токен и защищает от его подмены, verifier - секрет PKCE, провайдеру уходит только его хэш.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {S256Challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return metadata.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// tokenResponse - ответ token endpoint. Access токен провайдера нам не нужен - пользователь получает наши токены.
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange - обменивает код авторизации на ID токен. Без verifier, с которым начинался вход, провайдер код не примет.
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	// Публичный клиент передаёт только client_id, конфиденциальный - аутентифицируется секретом (client_secret_basic).
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	defer resp.Body.Close()
+
+	var tokens tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tokens); err != nil {
+		return "", fmt.Errorf("%w: статус %d", ErrExchange, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || tokens.Error != "" {
+		return "", fmt.Errorf("%w: %s %s", ErrExchange, tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return "", fmt.Errorf("%w: в ответе нет id_token", ErrExchange)
+	}
+
+	return tokens.IDToken, nil
+}
+
+// VerifyIDToken - проверяет подпись ID токена ключом провайдера, издателя, получателя, срок действия и nonce.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	_, err = jwt.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.key(ctx, kid, token.Method.Alg())
+	},
+		jwt.WithValidMethods(idTokenAlgs),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: нет sub", ErrInvalidIDToken)
+	}
+	// Токен, выпущенный для нескольких получателей, должен быть выпущен именно для нас.
+	if len(claims.Audience) > 1 && claims.AZP != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: azp не совпадает с client_id", ErrInvalidIDToken)
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce не совпадает", ErrInvalidIDToken)
+	}
+
+	return &claims, nil
+}
+
+// discover - читает discovery документ провайдера. Неудачная попытка не кэшируется: провайдер мог быть временно недоступен.
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var metadata Metadata
+	if err := getJSON(ctx, p.client, p.cfg.Issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	// Издатель в документе обязан совпадать с адресом, по которому документ получен.
+	if strings.TrimSuffix(metadata.Issuer, "/") != p.cfg.Issuer {
+		return nil, fmt.Errorf("%w: issuer %q не совпадает с %q", ErrDiscovery, metadata.Issuer, p.cfg.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: не указаны authorization_endpoint, token_endpoint или jwks_uri", ErrDiscovery)
+	}
+	// Провайдер, не заявивший методы PKCE, может их поддерживать, а заявивший без S256 - точно нет.
+	if len(metadata.CodeChallengeMethodsSupported) > 0 && !slices.Contains(metadata.CodeChallengeMethodsSupported, "S256") {
+		return nil, ErrPKCEUnsupported
+	}
+
+	p.metadata = &metadata
+	p.keys = newRemoteKeySet(metadata.JWKSURI, p.client)
+	return p.metadata, nil
+}
+
+// getJSON - GET запрос к провайдеру с разбором JSON ответа.
+func getJSON(ctx context.Context, client *http.Client, uri string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: статус %d", uri, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// flexBool - булево значение, которое часть провайдеров передаёт строкой "true".
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	case "false", "null":
+		*b = false
+	default:
+		return fmt.Errorf("некорректное булево значение %s", data)
+	}
+	return nil
+}
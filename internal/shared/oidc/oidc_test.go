@@ -0,0 +1,159 @@
+package oidc
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID    = "inventory"
+	testRedirectURL = "https://app.example.com/OIDCCallback"
+)
+
+// newTestProvider - заглушка провайдера на httptest сервере и клиент, настроенный на неё.
+func newTestProvider(t *testing.T, clientSecret string) (*Provider, *Stub) {
+	t.Helper()
+
+	var stub *Stub
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.Handler().ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	stub, err := NewStub(srv.URL, "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return New(&config.OIDCConfig{
+		Issuer:       srv.URL,
+		ClientID:     testClientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "email"},
+		HTTPTimeout:  5 * time.Second,
+	}), stub
+}
+
+// authorize - проходит страницу входа заглушки, как браузер пользователя, и возвращает параметры редиректа на OIDCCallback.
+func authorize(t *testing.T, p *Provider, state, nonce, verifier string) url.Values {
+	t.Helper()
+
+	loginURL, err := p.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(loginURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: статус %d, ожидался редирект", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := location.Scheme + "://" + location.Host + location.Path; got != testRedirectURL {
+		t.Fatalf("редирект на %s, ожидался %s", got, testRedirectURL)
+	}
+	return location.Query()
+}
+
+func TestProviderLogin(t *testing.T) {
+	for _, secret := range []string{"", "client-secret"} {
+		name := "публичный клиент"
+		if secret != "" {
+			name = "конфиденциальный клиент"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			p, _ := newTestProvider(t, secret)
+
+			callback := authorize(t, p, "state-1", "nonce-1", "verifier-1")
+			if callback.Get("state") != "state-1" {
+				t.Fatalf("state = %q, ожидался state-1", callback.Get("state"))
+			}
+
+			rawIDToken, err := p.Exchange(context.Background(), callback.Get("code"), "verifier-1")
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+
+			claims, err := p.VerifyIDToken(context.Background(), rawIDToken, "nonce-1")
+			if err != nil {
+				t.Fatalf("VerifyIDToken: %v", err)
+			}
+			if claims.Email != "user@example.com" || !claims.EmailVerified || claims.Subject == "" {
+				t.Fatalf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestProviderRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		verifier string // verifier при обмене кода, начинали вход с verifier-1
+		nonce    string // nonce при проверке ID токена, начинали вход с nonce-1
+		reuse    bool   // код уже обменян
+		wantErr  error
+	}{
+		{name: "чужой PKCE verifier", verifier: "verifier-2", nonce: "nonce-1", wantErr: ErrExchange},
+		{name: "без PKCE verifier", verifier: "", nonce: "nonce-1", wantErr: ErrExchange},
+		{name: "повторный обмен кода", verifier: "verifier-1", nonce: "nonce-1", reuse: true, wantErr: ErrExchange},
+		{name: "nonce не совпадает", verifier: "verifier-1", nonce: "nonce-2", wantErr: ErrInvalidIDToken},
+		{name: "без nonce", verifier: "verifier-1", nonce: "", wantErr: ErrInvalidIDToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newTestProvider(t, "")
+			ctx := context.Background()
+
+			code := authorize(t, p, "state-1", "nonce-1", "verifier-1").Get("code")
+			if tt.reuse {
+				if _, err := p.Exchange(ctx, code, "verifier-1"); err != nil {
+					t.Fatalf("первый обмен: %v", err)
+				}
+			}
+
+			rawIDToken, err := p.Exchange(ctx, code, tt.verifier)
+			if err == nil {
+				_, err = p.VerifyIDToken(ctx, rawIDToken, tt.nonce)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProviderRejectsForeignToken(t *testing.T) {
+	p, _ := newTestProvider(t, "")
+	other, _ := newTestProvider(t, "")
+	ctx := context.Background()
+
+	// Токен другого провайдера: издатель и ключ подписи не наши.
+	code := authorize(t, other, "state-1", "nonce-1", "verifier-1").Get("code")
+	rawIDToken, err := other.Exchange(ctx, code, "verifier-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	if _, err := p.VerifyIDToken(ctx, rawIDToken, "nonce-1"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("ошибка %v, ожидалась %v", err, ErrInvalidIDToken)
+	}
+}
@@ -0,0 +1,75 @@
+package passwords
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+// rangePrefixLength - длина префикса хэша, по которому группируются хэши, как в диапазонном API Pwned Passwords.
+const rangePrefixLength = 5
+
+// BreachedList - SHA-1 хэши утёкших паролей, разложенные по диапазонам: первые 5 символов хэша - ключ,
+// остальные - отсортированный список в диапазоне. Так же устроен k-anonymity API Pwned Passwords,
+// поэтому при необходимости локальный файл заменяется запросом диапазона по префиксу без изменения проверки.
+type BreachedList struct {
+	ranges map[string][]string
+}
+
+// LoadBreachedList - читает файл в формате выгрузки Pwned Passwords: по строке на хэш, "SHA1" или "SHA1:количество".
+// Регистр не важен, пустые строки и строки с # пропускаются.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть файл утёкших паролей: %w", err)
+	}
+	defer file.Close()
+
+	list := &BreachedList{ranges: map[string][]string{}}
+	count := 0
+
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("%s:%d: ожидается SHA-1 хэш в hex", path, lineNum)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("%s:%d: ожидается SHA-1 хэш в hex", path, lineNum)
+		}
+
+		prefix := hash[:rangePrefixLength]
+		list.ranges[prefix] = append(list.ranges[prefix], hash[rangePrefixLength:])
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл утёкших паролей: %w", err)
+	}
+
+	for prefix := range list.ranges {
+		slices.Sort(list.ranges[prefix])
+	}
+
+	slog.Info("Загружен список утёкших паролей", "path", path, "count", count)
+	return list, nil
+}
+
+// Contains - встречается ли пароль в утечках.
+func (b *BreachedList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	_, found := slices.BinarySearch(b.ranges[hash[:rangePrefixLength]], hash[rangePrefixLength:])
+	return found
+}
@@ -0,0 +1,100 @@
+package passwords
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"crypto/sha1"
+	"encoding/hex"
+	"golang.org/x/crypto/bcrypt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sha1Hex - SHA-1 пароля в hex, как в выгрузке Pwned Passwords.
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeBreachedFile - файл утёкших паролей во временном каталоге теста.
+func writeBreachedFile(t *testing.T, lines ...string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBreachedList(t *testing.T) {
+	path := writeBreachedFile(t,
+		"# выгрузка Pwned Passwords",
+		sha1Hex("password")+":3861493",
+		"",
+		strings.ToLower(sha1Hex("qwerty123")),
+		"  "+sha1Hex("letmein")+"  ",
+		// Тот же диапазон, что у password: внутри диапазона хэши ищутся по отсортированному списку.
+		sha1Hex("password")[:rangePrefixLength]+strings.Repeat("0", 35),
+	)
+
+	list, err := LoadBreachedList(path)
+	if err != nil {
+		t.Fatalf("LoadBreachedList: %v", err)
+	}
+
+	for password, want := range map[string]bool{
+		"password":  true,
+		"qwerty123": true,
+		"letmein":   true,
+		"Password":  false,
+		"passwor":   false,
+		"":          false,
+	} {
+		if got := list.Contains(password); got != want {
+			t.Errorf("Contains(%q) = %v, ожидалось %v", password, got, want)
+		}
+	}
+}
+
+func TestLoadBreachedListRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "не hex", line: strings.Repeat("Z", 40)},
+		{name: "короткий хэш", line: sha1Hex("password")[:39]},
+		{name: "хэш SHA-256", line: strings.Repeat("A", 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadBreachedList(writeBreachedFile(t, sha1Hex("password"), tt.line)); err == nil {
+				t.Fatal("некорректная строка должна отклоняться")
+			}
+		})
+	}
+
+	if _, err := LoadBreachedList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Fatal("отсутствующий файл должен отклоняться")
+	}
+}
+
+func TestPolicyCheckBreached(t *testing.T) {
+	policy, err := NewPolicy(&config.PasswordConfig{
+		MinLength:    1,
+		MaxLength:    64,
+		BreachedFile: writeBreachedFile(t, sha1Hex("password1")),
+	}, NewBcrypt(bcrypt.MinCost))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := violationRule(t, policy.Check("password1", nil)); got != "password_breached" {
+		t.Fatalf("нарушение %q, ожидалось password_breached", got)
+	}
+	if err := policy.Check("password2", nil); err != nil {
+		t.Fatalf("пароля нет в утечках: %v", err)
+	}
+}
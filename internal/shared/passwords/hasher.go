@@ -0,0 +1,78 @@
+package passwords
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownHash - хэш не распознан ни одним из известных алгоритмов.
+var ErrUnknownHash = errors.New("неизвестный формат хэша пароля")
+
+// Hasher - алгоритм хэширования паролей. Хэши самоописывающие: по строке видно алгоритм и параметры,
+// поэтому хэши разных алгоритмов могут храниться в одной колонке и проверяться без дополнительных данных.
+type Hasher interface {
+	// Hash - хэширует пароль со случайной солью.
+	Hash(password string) (string, error)
+	// Verify - проверяет пароль по хэшу. ErrUnknownHash - если хэш получен не этим алгоритмом.
+	Verify(password, hash string) (bool, error)
+	// NeedsRehash - хэш получен другим алгоритмом или более слабыми параметрами и его стоит пересчитать.
+	NeedsRehash(hash string) bool
+	// Supports - хэш получен этим алгоритмом.
+	Supports(hash string) bool
+}
+
+// NewHasher - хэширует выбранным в конфигурации алгоритмом, а проверяет любым известным:
+// хэши прежних алгоритмов продолжают работать, пока не будут пересчитаны при входе.
+func NewHasher(cfg *config.PasswordConfig) (Hasher, error) {
+	argon := NewArgon2id(Argon2idParams{
+		Memory:      cfg.Argon2Memory,
+		Iterations:  cfg.Argon2Iterations,
+		Parallelism: cfg.Argon2Parallelism,
+		SaltLength:  16,
+		KeyLength:   32,
+	})
+	bc := NewBcrypt(cfg.BcryptCost)
+
+	switch cfg.HashAlgorithm {
+	case config.PasswordHashArgon2id:
+		return &chain{primary: argon, known: []Hasher{argon, bc}}, nil
+	case config.PasswordHashBcrypt:
+		return &chain{primary: bc, known: []Hasher{bc, argon}}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный алгоритм хэширования паролей: %s", cfg.HashAlgorithm)
+	}
+}
+
+// chain - основной алгоритм для новых хэшей и все известные для проверки старых.
+type chain struct {
+	primary Hasher
+	known   []Hasher
+}
+
+func (c *chain) Hash(password string) (string, error) {
+	return c.primary.Hash(password)
+}
+
+func (c *chain) Verify(password, hash string) (bool, error) {
+	for _, h := range c.known {
+		if h.Supports(hash) {
+			return h.Verify(password, hash)
+		}
+	}
+	return false, ErrUnknownHash
+}
+
+// NeedsRehash - хэш получен не основным алгоритмом или основным, но с параметрами слабее текущих.
+func (c *chain) NeedsRehash(hash string) bool {
+	return c.primary.NeedsRehash(hash)
+}
+
+func (c *chain) Supports(hash string) bool {
+	for _, h := range c.known {
+		if h.Supports(hash) {
+			return true
+		}
+	}
+	return false
+}
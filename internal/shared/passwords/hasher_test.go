@@ -0,0 +1,195 @@
+package passwords
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+)
+
+// testArgon2Params - параметры с минимальными затратами, чтобы тесты шли быстро.
+var testArgon2Params = Argon2idParams{Memory: 64, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2id(t *testing.T) {
+	a := NewArgon2id(testArgon2Params)
+
+	hash, err := a.Hash("секретный пароль")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=2,p=1$") || !a.Supports(hash) {
+		t.Fatalf("хэш %q не в формате PHC", hash)
+	}
+
+	params, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		t.Fatalf("parseArgon2id: %v", err)
+	}
+	if params != testArgon2Params || len(salt) != 16 || len(key) != 32 {
+		t.Fatalf("параметры %+v, соль %d байт, ключ %d байт", params, len(salt), len(key))
+	}
+
+	// Соль случайная: хэши одного пароля различаются.
+	if other, _ := a.Hash("секретный пароль"); other == hash {
+		t.Fatal("хэши одного пароля совпадают")
+	}
+
+	for password, want := range map[string]bool{"секретный пароль": true, "секретный пароль ": false, "": false} {
+		ok, err := a.Verify(password, hash)
+		if err != nil || ok != want {
+			t.Fatalf("Verify(%q) = %v, %v, ожидалось %v", password, ok, err, want)
+		}
+	}
+
+	// Хэш проверяется с параметрами из него самого, даже если текущие другие.
+	stronger := NewArgon2id(Argon2idParams{Memory: 128, Iterations: 3, Parallelism: 2, SaltLength: 16, KeyLength: 64})
+	if ok, err := stronger.Verify("секретный пароль", hash); !ok || err != nil {
+		t.Fatalf("хэш со старыми параметрами не прошёл проверку: %v, %v", ok, err)
+	}
+}
+
+func TestParseArgon2idRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "bcrypt", hash: "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"},
+		{name: "argon2i", hash: "$argon2i$v=19$m=64,t=2,p=1$c29tZXNhbHQ$a2V5"},
+		{name: "не та версия", hash: "$argon2id$v=16$m=64,t=2,p=1$c29tZXNhbHQ$a2V5"},
+		{name: "без параметров", hash: "$argon2id$v=19$c29tZXNhbHQ$a2V5"},
+		{name: "некорректные параметры", hash: "$argon2id$v=19$m=x,t=2,p=1$c29tZXNhbHQ$a2V5"},
+		{name: "соль не в base64", hash: "$argon2id$v=19$m=64,t=2,p=1$!!!$a2V5"},
+		{name: "пустой ключ", hash: "$argon2id$v=19$m=64,t=2,p=1$c29tZXNhbHQ$"},
+		{name: "пустая строка", hash: ""},
+	}
+
+	a := NewArgon2id(testArgon2Params)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := parseArgon2id(tt.hash); !errors.Is(err, ErrUnknownHash) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, ErrUnknownHash)
+			}
+			if _, err := a.Verify("password", tt.hash); !errors.Is(err, ErrUnknownHash) {
+				t.Fatalf("Verify: ошибка %v, ожидалась %v", err, ErrUnknownHash)
+			}
+			if !a.NeedsRehash(tt.hash) {
+				t.Fatal("нераспознанный хэш нужно пересчитать")
+			}
+		})
+	}
+}
+
+func TestArgon2idNeedsRehash(t *testing.T) {
+	current := NewArgon2id(testArgon2Params)
+
+	tests := []struct {
+		name   string
+		params Argon2idParams
+		want   bool
+	}{
+		{name: "текущие параметры", params: testArgon2Params, want: false},
+		{name: "меньше памяти", params: Argon2idParams{Memory: 32, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32}, want: true},
+		{name: "меньше проходов", params: Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}, want: true},
+		{name: "короче ключ", params: Argon2idParams{Memory: 64, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 16}, want: true},
+		{name: "параметры сильнее текущих", params: Argon2idParams{Memory: 128, Iterations: 3, Parallelism: 1, SaltLength: 16, KeyLength: 32}, want: false},
+		{name: "другое число потоков", params: Argon2idParams{Memory: 64, Iterations: 2, Parallelism: 2, SaltLength: 16, KeyLength: 32}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := NewArgon2id(tt.params).Hash("password")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := current.NeedsRehash(hash); got != tt.want {
+				t.Fatalf("NeedsRehash = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBcryptNeedsRehash(t *testing.T) {
+	current := NewBcrypt(bcrypt.MinCost + 1)
+
+	weak, err := NewBcrypt(bcrypt.MinCost).Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	same, err := current.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !current.NeedsRehash(weak) {
+		t.Fatal("хэш с меньшей стоимостью нужно пересчитать")
+	}
+	if current.NeedsRehash(same) {
+		t.Fatal("хэш с текущей стоимостью пересчитывать не нужно")
+	}
+	if ok, err := current.Verify("password", weak); !ok || err != nil {
+		t.Fatalf("хэш с меньшей стоимостью не прошёл проверку: %v, %v", ok, err)
+	}
+}
+
+func TestHasherChain(t *testing.T) {
+	cfg := func(algorithm string) *config.PasswordConfig {
+		return &config.PasswordConfig{
+			HashAlgorithm: algorithm,
+			Argon2Memory:  64, Argon2Iterations: 2, Argon2Parallelism: 1,
+			BcryptCost: bcrypt.MinCost,
+		}
+	}
+
+	argonHasher, err := NewHasher(cfg(config.PasswordHashArgon2id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHasher, err := NewHasher(cfg(config.PasswordHashBcrypt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Переход с bcrypt на Argon2id: старый хэш проверяется, но помечается на пересчёт, а новый хэш - уже Argon2id.
+	legacy, err := bcryptHasher.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := argonHasher.Verify("password", legacy); !ok || err != nil {
+		t.Fatalf("хэш bcrypt не прошёл проверку: %v, %v", ok, err)
+	}
+	if ok, _ := argonHasher.Verify("wrong", legacy); ok {
+		t.Fatal("неверный пароль прошёл проверку по хэшу bcrypt")
+	}
+	if !argonHasher.NeedsRehash(legacy) {
+		t.Fatal("хэш bcrypt нужно пересчитать в Argon2id")
+	}
+
+	rehashed, err := argonHasher.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rehashed, argon2idPrefix) || argonHasher.NeedsRehash(rehashed) {
+		t.Fatalf("пересчитанный хэш %q должен быть Argon2id с текущими параметрами", rehashed)
+	}
+
+	// Обратный переход тоже работает: хэши Argon2id проверяются и пересчитываются в bcrypt.
+	if ok, err := bcryptHasher.Verify("password", rehashed); !ok || err != nil {
+		t.Fatalf("хэш Argon2id не прошёл проверку: %v, %v", ok, err)
+	}
+	if !bcryptHasher.NeedsRehash(rehashed) {
+		t.Fatal("хэш Argon2id нужно пересчитать в bcrypt")
+	}
+
+	// Хэш неизвестного алгоритма не проверяется ни одним из них.
+	if _, err := argonHasher.Verify("password", "$1$salt$hash"); !errors.Is(err, ErrUnknownHash) {
+		t.Fatalf("ошибка %v, ожидалась %v", err, ErrUnknownHash)
+	}
+	if argonHasher.Supports("$1$salt$hash") {
+		t.Fatal("хэш неизвестного алгоритма не поддерживается")
+	}
+
+	if _, err := NewHasher(cfg("md5")); err == nil {
+		t.Fatal("неизвестный алгоритм должен отклоняться")
+	}
+}
@@ -0,0 +1,173 @@
+package passwords
+
+import (
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/dto/config"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrPolicy - пароль не соответствует политике. Что именно не так - в Violation.
+var ErrPolicy = errors.New("пароль не соответствует политике")
+
+// Violation - нарушение политики паролей. Rule - код нарушения вида password_<что не так>,
+// Param - его параметр (например минимальная длина), если он есть.
+type Violation struct {
+	Rule  string
+	Param string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s", ErrPolicy, v.Rule)
+}
+
+func (v *Violation) Unwrap() error {
+	return ErrPolicy
+}
+
+// minPersonalPartLength - части email и имени короче этого в пароле допускаются:
+// иначе совпадение пары букв с именем не давало бы задать пароль.
+const minPersonalPartLength = 3
+
+// Policy - политика паролей: длина, классы символов, отсутствие личных данных пользователя,
+// история прежних паролей и список утёкших паролей.
+type Policy struct {
+	cfg      *config.PasswordConfig
+	hasher   Hasher
+	breached *BreachedList // nil - проверка по утечкам выключена
+}
+
+// NewPolicy - создаёт политику по конфигурации. hasher сверяет пароль с историей.
+// Список утёкших паролей читается из файла один раз, при старте.
+func NewPolicy(cfg *config.PasswordConfig, hasher Hasher) (*Policy, error) {
+	policy := &Policy{cfg: cfg, hasher: hasher}
+
+	if cfg.BreachedFile != "" {
+		breached, err := LoadBreachedList(cfg.BreachedFile)
+		if err != nil {
+			return nil, err
+		}
+		policy.breached = breached
+	}
+
+	return policy, nil
+}
+
+// Check - проверяет новый пароль пользователя: формат, отсутствие email и имени, отсутствие в утечках.
+// История паролей проверяется отдельно, в CheckHistory: для неё нужны хэши из БД.
+func (p *Policy) Check(password string, user *dto.User) error {
+	if err := CheckFormat(p.cfg, password); err != nil {
+		return err
+	}
+	if err := checkPersonal(password, user); err != nil {
+		return err
+	}
+	if p.breached != nil && p.breached.Contains(password) {
+		return &Violation{Rule: "password_breached"}
+	}
+	return nil
+}
+
+// HistorySize - сколько прежних паролей пользователя нельзя использовать повторно.
+func (p *Policy) HistorySize() int {
+	return p.cfg.HistorySize
+}
+
+// CheckHistory - проверяет, что пароль не совпадает ни с одним из хэшей: текущего и прежних паролей пользователя.
+func (p *Policy) CheckHistory(password string, hashes []string) error {
+	for _, hash := range hashes {
+		// Хэш в неизвестном формате - не совпадение: он не мог быть получен из этого пароля нашими алгоритмами.
+		if ok, _ := p.hasher.Verify(password, hash); ok {
+			return &Violation{Rule: "password_reused", Param: strconv.Itoa(p.cfg.HistorySize + 1)}
+		}
+	}
+	return nil
+}
+
+// CheckFormat - проверяет длину и обязательные классы символов. Не зависит от пользователя,
+// поэтому применяется и при проверке запроса, до обращения к сервисам.
+func CheckFormat(cfg *config.PasswordConfig, password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < cfg.MinLength {
+		return &Violation{Rule: "password_min_length", Param: strconv.Itoa(cfg.MinLength)}
+	}
+	if length > cfg.MaxLength {
+		return &Violation{Rule: "password_max_length", Param: strconv.Itoa(cfg.MaxLength)}
+	}
+
+	var hasLower, hasUpper, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLetter(r):
+			// Буквы без регистра считаются строчными, чтобы пароль на таких алфавитах проходил требование letter.
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsSpace(r):
+			hasSpecial = true
+		}
+	}
+
+	for _, class := range cfg.RequiredClasses {
+		var ok bool
+		switch class {
+		case config.PasswordClassLower:
+			ok = hasLower
+		case config.PasswordClassUpper:
+			ok = hasUpper
+		case config.PasswordClassLetter:
+			ok = hasLower || hasUpper
+		case config.PasswordClassDigit:
+			ok = hasDigit
+		case config.PasswordClassSpecial:
+			ok = hasSpecial
+		}
+		if !ok {
+			return &Violation{Rule: "password_" + class}
+		}
+	}
+
+	return nil
+}
+
+// checkPersonal - пароль не содержит email пользователя, его часть до @ или её слова, имя и фамилию.
+// Такой пароль подбирается первым же делом.
+func checkPersonal(password string, user *dto.User) error {
+	if user == nil {
+		return nil
+	}
+	password = strings.ToLower(password)
+
+	local, _, _ := strings.Cut(strings.ToLower(user.Email), "@")
+	emailParts := append([]string{local}, strings.FieldsFunc(local, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})...)
+	if containsAny(password, emailParts) {
+		return &Violation{Rule: "password_contains_email"}
+	}
+
+	if containsAny(password, []string{strings.ToLower(user.FirstName), strings.ToLower(user.LastName)}) {
+		return &Violation{Rule: "password_contains_name"}
+	}
+
+	return nil
+}
+
+// containsAny - password содержит хотя бы одну из частей не короче minPersonalPartLength.
+func containsAny(password string, parts []string) bool {
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if utf8.RuneCountInString(part) >= minPersonalPartLength && strings.Contains(password, part) {
+			return true
+		}
+	}
+	return false
+}
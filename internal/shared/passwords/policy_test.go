@@ -0,0 +1,139 @@
+package passwords
+
+import (
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/dto/config"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+)
+
+// violationRule - код нарушения политики или пусто, если пароль её прошёл.
+func violationRule(t *testing.T, err error) string {
+	t.Helper()
+
+	if err == nil {
+		return ""
+	}
+	var violation *Violation
+	if !errors.As(err, &violation) || !errors.Is(err, ErrPolicy) {
+		t.Fatalf("ошибка %v не нарушение политики", err)
+	}
+	return violation.Rule
+}
+
+func TestCheckFormat(t *testing.T) {
+	cfg := &config.PasswordConfig{
+		MinLength: 8,
+		MaxLength: 16,
+		RequiredClasses: []string{
+			config.PasswordClassLetter, config.PasswordClassDigit, config.PasswordClassSpecial,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		classes  []string // nil - классы из cfg
+		wantRule string
+	}{
+		{name: "подходит", password: "abcdef1!"},
+		{name: "короткий", password: "abc1!", wantRule: "password_min_length"},
+		{name: "длинный", password: "abcdefghijklmno1!", wantRule: "password_max_length"},
+		{name: "длина в символах, а не байтах", password: "пароль1!"},
+		{name: "без цифры", password: "abcdefg!", wantRule: "password_digit"},
+		{name: "без спецсимвола", password: "abcdefg1", wantRule: "password_special"},
+		{name: "пробел не спецсимвол", password: "abcdef 1", wantRule: "password_special"},
+		{name: "без буквы", password: "1234567!", wantRule: "password_letter"},
+		{name: "буквы без регистра", password: "日本語日本語1!"},
+
+		{name: "нет заглавной", password: "abcdefg1", classes: []string{config.PasswordClassUpper}, wantRule: "password_upper"},
+		{name: "нет строчной", password: "ABCDEFG1", classes: []string{config.PasswordClassLower}, wantRule: "password_lower"},
+		{name: "обе в регистре", password: "AbcdefG1", classes: []string{config.PasswordClassLower, config.PasswordClassUpper}},
+		{name: "без требований к классам", password: "        ", classes: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *cfg
+			if tt.classes != nil {
+				c.RequiredClasses = tt.classes
+			}
+			if got := violationRule(t, CheckFormat(&c, tt.password)); got != tt.wantRule {
+				t.Fatalf("нарушение %q, ожидалось %q", got, tt.wantRule)
+			}
+		})
+	}
+
+	// В параметре нарушения - требование, которое клиент покажет пользователю.
+	var violation *Violation
+	if !errors.As(CheckFormat(cfg, "a1!"), &violation) || violation.Param != "8" {
+		t.Fatalf("нарушение %+v, ожидалась минимальная длина 8", violation)
+	}
+}
+
+func TestPolicyCheckPersonal(t *testing.T) {
+	policy, err := NewPolicy(&config.PasswordConfig{MinLength: 1, MaxLength: 64}, NewBcrypt(bcrypt.MinCost))
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &dto.User{Email: "Ivan.Petrov-77@example.com", FirstName: "Анна", LastName: "Li"}
+
+	tests := []struct {
+		name     string
+		password string
+		user     *dto.User
+		wantRule string
+	}{
+		{name: "без личных данных", password: "correct horse battery", user: user},
+		{name: "часть email до @", password: "my-ivan.petrov-77-pass", user: user, wantRule: "password_contains_email"},
+		{name: "слово из email без учёта регистра", password: "SuperPETROV", user: user, wantRule: "password_contains_email"},
+		{name: "цифры из email короче трёх знаков", password: "horse77battery", user: user},
+		{name: "имя", password: "ЛюбимаяАнна2024", user: user, wantRule: "password_contains_name"},
+		{name: "короткие части не проверяются", password: "lion king", user: user},
+		{name: "домен email допускается", password: "example-password", user: user},
+		{name: "без пользователя", password: "ivan.petrov", user: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := violationRule(t, policy.Check(tt.password, tt.user)); got != tt.wantRule {
+				t.Fatalf("нарушение %q, ожидалось %q", got, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckHistory(t *testing.T) {
+	hasher := NewBcrypt(bcrypt.MinCost)
+	policy, err := NewPolicy(&config.PasswordConfig{HistorySize: 2}, hasher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hashes []string
+	for _, password := range []string{"current", "previous", "oldest"} {
+		hash, err := hasher.Hash(password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, hash)
+	}
+	// Хэш неизвестного формата не мешает проверке остальных.
+	hashes = append([]string{"$unknown$"}, hashes...)
+
+	for _, password := range []string{"current", "previous", "oldest"} {
+		err := policy.CheckHistory(password, hashes)
+		var violation *Violation
+		if !errors.As(err, &violation) || violation.Rule != "password_reused" || violation.Param != "3" {
+			t.Fatalf("%q: ошибка %v, ожидалось нарушение password_reused с параметром 3", password, err)
+		}
+	}
+
+	if err := policy.CheckHistory("brand new", hashes); err != nil {
+		t.Fatalf("новый пароль: %v", err)
+	}
+	if err := policy.CheckHistory("current", nil); err != nil {
+		t.Fatalf("без истории: %v", err)
+	}
+}
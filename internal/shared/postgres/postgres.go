@@ -2,33 +2,35 @@ package postgres
 
 import (
 	"DBManager/internal/shared/config"
-	"DBManager/internal/shared/dto"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"log/slog"
 )
 
+// InitPostgres - подключается к Postgres. Схема БД управляется пакетом migrations (команда migrate).
 func InitPostgres() (*gorm.DB, error) {
 	// Получение строки подключения к БД из конфига
-	cfg, err := config.PgSQLConfig()
-	if err != nil {
-		return nil, err
-	}
+	cfg := config.PgSQLConfig()
 
 	// Подключение к базе данных
-	db, err := gorm.Open(postgres.Open(cfg.Addr), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(cfg.Addr), &gorm.Config{
+		TranslateError: true, // Ошибки драйвера приводим к ошибкам gorm (ErrDuplicatedKey и т.д.)
+	})
 	if err != nil {
 		slog.Error("Не удалось подключиться к Postgres.", "Ошибка", err)
 		return nil, err
 	}
 
-	// Применяем миграции к БД, мигрируя следующие таблицы:
-	if err := db.AutoMigrate(&dto.User{}); err != nil {
-		slog.Error("Не удалось подключиться к Postgres.", "Ошибка", err)
-		return nil, err
-	}
-
 	slog.Info("Соединение с Postgres успешно установлено")
 
 	return db, nil
 }
+
+// Close - закрывает пул соединений с Postgres. Вызывается при остановке, когда запросов к БД больше не будет.
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery - раз в сколько вызовов из памяти удаляются ключи, лимит которых полностью восстановился.
+const sweepEvery = 1000
+
+// MemoryLimiter - ограничитель в памяти процесса, для тестов и запуска без Redis.
+// Лимиты не разделяются между экземплярами приложения.
+type MemoryLimiter struct {
+	mu    sync.Mutex
+	tats  map[string]int64
+	calls int
+	now   func() time.Time // Часы, в тестах подменяются
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{tats: make(map[string]int64), now: time.Now}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, policy config.RateLimitPolicy) (*Result, error) {
+	now := l.now().UnixMilli()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls++
+	if l.calls%sweepEvery == 0 {
+		for k, tat := range l.tats {
+			if tat <= now {
+				delete(l.tats, k)
+			}
+		}
+	}
+
+	tat, res := gcra(policy, l.tats[key], now)
+	l.tats[key] = tat
+
+	return res, nil
+}
@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	// 5 запросов в секунду: интервал 200ms, всплеск до 5 запросов подряд.
+	policy := config.RateLimitPolicy{Name: "test", Limit: 5, Period: time.Second}
+
+	type step struct {
+		at         time.Duration // Время запроса от начала теста
+		key        string
+		allowed    bool
+		remaining  int
+		resetAfter time.Duration
+		retryAfter time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "всплеск до лимита, затем отказ",
+			steps: []step{
+				{at: 0, key: "a", allowed: true, remaining: 4, resetAfter: 200 * time.Millisecond},
+				{at: 0, key: "a", allowed: true, remaining: 3, resetAfter: 400 * time.Millisecond},
+				{at: 0, key: "a", allowed: true, remaining: 2, resetAfter: 600 * time.Millisecond},
+				{at: 0, key: "a", allowed: true, remaining: 1, resetAfter: 800 * time.Millisecond},
+				{at: 0, key: "a", allowed: true, remaining: 0, resetAfter: time.Second},
+				{at: 0, key: "a", allowed: false, remaining: 0, resetAfter: time.Second, retryAfter: 200 * time.Millisecond},
+			},
+		},
+		{
+			name: "Retry-After уменьшается со временем, лимит восстанавливается по интервалу",
+			steps: []step{
+				{at: 0, key: "a", allowed: true, remaining: 4, resetAfter: 200 * time.Millisecond},
+				{at: 0, key: "a", allowed: true, remaining: 3, resetAfter: 400 * time.Millisecond},
+				{at: 0, key: "a", allowed: true, remaining: 2, resetAfter: 600 * time.Millisecond},
+				{at: 0, key: "a", allowed: true, remaining: 1, resetAfter: 800 * time.Millisecond},
+				{at: 0, key: "a", allowed: true, remaining: 0, resetAfter: time.Second},
+				{at: 50 * time.Millisecond, key: "a", allowed: false, remaining: 0, resetAfter: 950 * time.Millisecond, retryAfter: 150 * time.Millisecond},
+				{at: 200 * time.Millisecond, key: "a", allowed: true, remaining: 0, resetAfter: time.Second},
+				{at: 200 * time.Millisecond, key: "a", allowed: false, remaining: 0, resetAfter: time.Second, retryAfter: 200 * time.Millisecond},
+			},
+		},
+		{
+			name: "после полного периода доступен весь всплеск",
+			steps: []step{
+				{at: 0, key: "a", allowed: true, remaining: 4, resetAfter: 200 * time.Millisecond},
+				{at: 0, key: "a", allowed: true, remaining: 3, resetAfter: 400 * time.Millisecond},
+				{at: 2 * time.Second, key: "a", allowed: true, remaining: 4, resetAfter: 200 * time.Millisecond},
+			},
+		},
+		{
+			name: "ключи считаются независимо",
+			steps: []step{
+				{at: 0, key: "a", allowed: true, remaining: 4, resetAfter: 200 * time.Millisecond},
+				{at: 0, key: "a", allowed: true, remaining: 3, resetAfter: 400 * time.Millisecond},
+				{at: 0, key: "b", allowed: true, remaining: 4, resetAfter: 200 * time.Millisecond},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			now := start
+			limiter := NewMemoryLimiter()
+			limiter.now = func() time.Time { return now }
+
+			for i, s := range tt.steps {
+				now = start.Add(s.at)
+
+				res, err := limiter.Allow(context.Background(), s.key, policy)
+				if err != nil {
+					t.Fatalf("шаг %d: %v", i, err)
+				}
+				if res.Allowed != s.allowed {
+					t.Errorf("шаг %d: Allowed = %v, ожидалось %v", i, res.Allowed, s.allowed)
+				}
+				if res.Limit != policy.Limit {
+					t.Errorf("шаг %d: Limit = %d, ожидалось %d", i, res.Limit, policy.Limit)
+				}
+				if res.Remaining != s.remaining {
+					t.Errorf("шаг %d: Remaining = %d, ожидалось %d", i, res.Remaining, s.remaining)
+				}
+				if res.ResetAfter != s.resetAfter {
+					t.Errorf("шаг %d: ResetAfter = %s, ожидалось %s", i, res.ResetAfter, s.resetAfter)
+				}
+				if res.RetryAfter != s.retryAfter {
+					t.Errorf("шаг %d: RetryAfter = %s, ожидалось %s", i, res.RetryAfter, s.retryAfter)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// Limiter - ограничитель частоты запросов по алгоритму GCRA (Generic Cell Rate Algorithm).
+// Для каждого ключа хранится только теоретическое время прибытия следующего запроса (TAT).
+type Limiter interface {
+	Allow(ctx context.Context, key string, policy config.RateLimitPolicy) (*Result, error)
+}
+
+// Result - решение ограничителя и данные для заголовков RateLimit-*.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int           // Сколько запросов ещё можно сделать подряд
+	ResetAfter time.Duration // Через сколько лимит восстановится полностью
+	RetryAfter time.Duration // Через сколько можно повторить отклонённый запрос
+}
+
+// New - создаёт Limiter по драйверу из конфигурации.
+func New(cfg *config.RateLimitConfig, rDB *redis.Client) (Limiter, error) {
+	switch cfg.Driver {
+	case "redis", "":
+		return NewRedisLimiter(rDB), nil
+	case "memory":
+		return NewMemoryLimiter(), nil
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер ограничения частоты запросов: %s", cfg.Driver)
+	}
+}
+
+// gcra - принимает решение по запросу в момент now при текущем TAT. Все времена - в миллисекундах.
+// Возвращает новый TAT (для отклонённого запроса он не меняется) и результат.
+func gcra(policy config.RateLimitPolicy, tat, now int64) (int64, *Result) {
+	interval := emissionInterval(policy)
+	tolerance := interval * int64(policy.Limit)
+
+	if tat < now {
+		tat = now
+	}
+
+	newTat := tat + interval
+	if allowAt := newTat - tolerance; allowAt > now {
+		return tat, result(policy, false, tat, now, allowAt-now)
+	}
+
+	return newTat, result(policy, true, newTat, now, 0)
+}
+
+// result - вычисляет оставшийся лимит по TAT: каждый интервал между now и TAT занимает один запрос.
+func result(policy config.RateLimitPolicy, allowed bool, tat, now, retryAfter int64) *Result {
+	interval := emissionInterval(policy)
+	tolerance := interval * int64(policy.Limit)
+
+	remaining := int((tolerance - (tat - now)) / interval)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &Result{
+		Allowed:    allowed,
+		Limit:      policy.Limit,
+		Remaining:  remaining,
+		ResetAfter: time.Duration(tat-now) * time.Millisecond,
+		RetryAfter: time.Duration(retryAfter) * time.Millisecond,
+	}
+}
+
+// emissionInterval - интервал между запросами при равномерной нагрузке, не меньше миллисекунды.
+func emissionInterval(policy config.RateLimitPolicy) int64 {
+	return max(policy.Period.Milliseconds()/int64(policy.Limit), 1)
+}
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"context"
+	"github.com/redis/go-redis/v9"
+)
+
+// gcraScript - атомарное решение GCRA в Redis. Время берётся из Redis, чтобы экземпляры приложения
+// с разными часами не расходились. Алгоритм повторяет функцию gcra.
+var gcraScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local tat = tonumber(redis.call('GET', KEYS[1])) or now
+if tat < now then
+	tat = now
+end
+
+local newTat = tat + interval
+if newTat - tolerance > now then
+	return {0, tat, now}
+end
+
+redis.call('SET', KEYS[1], newTat, 'PX', newTat - now)
+return {1, newTat, now}
+`)
+
+type RedisLimiter struct {
+	rDB *redis.Client
+}
+
+func NewRedisLimiter(rDB *redis.Client) *RedisLimiter {
+	return &RedisLimiter{rDB: rDB}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, policy config.RateLimitPolicy) (*Result, error) {
+	interval := emissionInterval(policy)
+
+	values, err := gcraScript.Run(ctx, l.rDB, []string{rateLimitKey(key)}, interval, interval*int64(policy.Limit)).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, tat, now := values[0] == 1, values[1], values[2]
+	if allowed {
+		return result(policy, true, tat, now, 0), nil
+	}
+	return result(policy, false, tat, now, tat+interval-interval*int64(policy.Limit)-now), nil
+}
+
+func rateLimitKey(key string) string {
+	return "RateLimit:" + key
+}
@@ -0,0 +1,99 @@
+package rbac
+
+// Role - роль пользователя, определяющая набор его прав.
+type Role string
+
+// Permission - право на выполнение группы действий.
+type Permission string
+
+const (
+	RoleAdmin            Role = "admin"
+	RoleWarehouseManager Role = "warehouse_manager"
+	RoleClerk            Role = "clerk"
+	RoleAuditor          Role = "auditor"
+	RoleReadOnly         Role = "read_only"
+)
+
+const (
+	PermItemsRead       Permission = "items:read"
+	PermItemsWrite      Permission = "items:write"
+	PermWarehousesRead  Permission = "warehouses:read"
+	PermWarehousesWrite Permission = "warehouses:write"
+	PermStockRead       Permission = "stock:read"
+	PermStockAdjust     Permission = "stock:adjust" // Корректировки и исправление остатков по журналу
+	PermStockAudit      Permission = "stock:audit"  // Сверка остатков с журналом
+	PermMovementsRead   Permission = "movements:read"
+	PermMovementsWrite  Permission = "movements:write"
+	PermUsersManage     Permission = "users:manage"
+)
+
+// mfaPermissions - права на изменение остатков. При включённом MFA_ENFORCE они действуют
+// только в сессиях, при входе в которые пройдена вторая ступень.
+var mfaPermissions = toSet(PermMovementsWrite, PermStockAdjust)
+
+// DefaultRole - роль, которая выдаётся новым пользователям.
+const DefaultRole = RoleReadOnly
+
+// matrix - матрица прав: роль -> множество разрешённых действий.
+var matrix = map[Role]map[Permission]struct{}{
+	RoleAdmin: toSet(
+		PermItemsRead, PermItemsWrite, PermWarehousesRead, PermWarehousesWrite,
+		PermStockRead, PermStockAdjust, PermStockAudit, PermMovementsRead, PermMovementsWrite,
+		PermUsersManage,
+	),
+	RoleWarehouseManager: toSet(
+		PermItemsRead, PermItemsWrite, PermWarehousesRead, PermWarehousesWrite,
+		PermStockRead, PermStockAdjust, PermStockAudit, PermMovementsRead, PermMovementsWrite,
+	),
+	RoleClerk: toSet(
+		PermItemsRead, PermWarehousesRead, PermStockRead, PermMovementsRead, PermMovementsWrite,
+	),
+	RoleAuditor: toSet(
+		PermItemsRead, PermWarehousesRead, PermStockRead, PermStockAudit, PermMovementsRead,
+	),
+	RoleReadOnly: toSet(
+		PermItemsRead, PermWarehousesRead, PermStockRead,
+	),
+}
+
+// Can - проверяет, есть ли у роли указанное право. Неизвестная роль не имеет прав.
+func Can(role Role, permission Permission) bool {
+	_, ok := matrix[role][permission]
+	return ok
+}
+
+// IsValidPermission - проверяет, что право существует. Администратор имеет все права.
+func IsValidPermission(permission Permission) bool {
+	return Can(RoleAdmin, permission)
+}
+
+// RequiresMFA - требует ли право второй ступени при входе.
+func RequiresMFA(permission Permission) bool {
+	_, ok := mfaPermissions[permission]
+	return ok
+}
+
+// IsValid - проверяет, что роль существует в матрице прав.
+func IsValid(role Role) bool {
+	_, ok := matrix[role]
+	return ok
+}
+
+// Resolve - определяет действующую роль пользователя. Флаг IsAdmin имеет приоритет над сохранённой ролью.
+func Resolve(role string, isAdmin bool) Role {
+	if isAdmin {
+		return RoleAdmin
+	}
+	if IsValid(Role(role)) {
+		return Role(role)
+	}
+	return DefaultRole
+}
+
+func toSet(permissions ...Permission) map[Permission]struct{} {
+	set := make(map[Permission]struct{}, len(permissions))
+	for _, p := range permissions {
+		set[p] = struct{}{}
+	}
+	return set
+}
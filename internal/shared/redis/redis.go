@@ -3,12 +3,13 @@ package redis
 import (
 	"DBManager/internal/shared/config"
 	"context"
+	"fmt"
 	"github.com/redis/go-redis/v9"
-	"log"
 	"log/slog"
 )
 
-func InitRedis(ctx context.Context) *redis.Client {
+// InitRedis - подключается к Redis и проверяет соединение. Клиент закрывается вызывающим при остановке приложения.
+func InitRedis(ctx context.Context) (*redis.Client, error) {
 	cfg := config.RedisConfig()
 
 	rDB := redis.NewClient(&redis.Options{
@@ -18,10 +19,11 @@ func InitRedis(ctx context.Context) *redis.Client {
 	})
 
 	if _, err := rDB.Ping(ctx).Result(); err != nil {
-		log.Fatalf("Не удалось подключиться к Redis: %v", err)
+		_ = rDB.Close()
+		return nil, fmt.Errorf("не удалось подключиться к Redis: %w", err)
 	}
 
 	slog.Info("Соединение с Redis успешно установлено")
 
-	return rDB
+	return rDB, nil
 }
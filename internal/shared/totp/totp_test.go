@@ -0,0 +1,101 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret - ключ из тестовых векторов RFC 6238 (приложение B) для HMAC-SHA1 в base32.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeRFC6238(t *testing.T) {
+	// В RFC коды восьмизначные, у нас шестизначные - это их последние шесть цифр.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},          // 94287082
+		{unix: 1111111109, want: "081804"},  // 07081804
+		{unix: 1111111111, want: "050471"},  // 14050471
+		{unix: 1234567890, want: "005924"},  // 89005924
+		{unix: 2000000000, want: "279037"},  // 69279037
+		{unix: 20000000000, want: "353130"}, // 65353130
+	}
+
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("Code: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("%s, ожидался %s", got, tt.want)
+			}
+
+			// Секрет принимается и в нижнем регистре: так его иногда вводят вручную.
+			lower, err := Code(strings.ToLower(rfcSecret), Step(time.Unix(tt.unix, 0)))
+			if err != nil || lower != tt.want {
+				t.Fatalf("секрет в нижнем регистре: %s, %v", lower, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	codeAt := func(step int64) string {
+		code, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "текущий интервал", code: codeAt(current), wantStep: current, wantOK: true},
+		{name: "предыдущий интервал", code: codeAt(current - 1), wantStep: current - 1, wantOK: true},
+		{name: "следующий интервал", code: codeAt(current + 1), wantStep: current + 1, wantOK: true},
+		{name: "два интервала назад", code: codeAt(current - 2)},
+		{name: "два интервала вперёд", code: codeAt(current + 2)},
+		{name: "пробелы вокруг кода", code: " " + codeAt(current) + "\n", wantStep: current, wantOK: true},
+		{name: "короткий код", code: codeAt(current)[:5]},
+		{name: "пустой код", code: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("Validate = %d, %v, ожидалось %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := Validate("не base32", codeAt(current), now); ok {
+		t.Fatal("некорректный секрет не должен принимать коды")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("секрет не в base32: %v", err)
+	}
+	if len(key) != secretLen {
+		t.Fatalf("длина ключа %d байт, ожидалось %d", len(key), secretLen)
+	}
+}
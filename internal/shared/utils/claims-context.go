@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"DBManager/internal/shared/dto"
+	"context"
+)
+
+// claimsKey - неэкспортируемый тип ключа, чтобы не пересекаться с ключами других пакетов.
+type claimsKey struct{}
+
+// ContextWithClaims - кладёт данные Access Token в контекст запроса.
+func ContextWithClaims(ctx context.Context, claims *dto.AccessToken) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext - достаёт данные Access Token, положенные middleware авторизации.
+func ClaimsFromContext(ctx context.Context) (*dto.AccessToken, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*dto.AccessToken)
+	return claims, ok && claims != nil
+}
+
+// UserIDFromContext - возвращает id авторизованного пользователя.
+func UserIDFromContext(ctx context.Context) (int, bool) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	return claims.UserID, true
+}
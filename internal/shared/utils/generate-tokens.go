@@ -2,6 +2,7 @@ package utils
 
 import (
 	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/jwtkeys"
 	"crypto/sha256"
 	"encoding/hex"
 	"github.com/golang-jwt/jwt/v5"
@@ -9,15 +10,18 @@ import (
 	"time"
 )
 
-// GenerateAccessToken - генерирует Access token и вшивает в него данные, применяя метод хеша sha256.
+// GenerateAccessToken - генерирует Access token, вшивает в него данные и подписывает ключом key.
 // Возвращает 3 переменные - сформированный токен в строке, уникальный идентификатор access токена и возможную ошибку.
-func GenerateAccessToken(userID int, expiresIn time.Duration, secret []byte) (string, string, error) {
+func GenerateAccessToken(userID int, role string, sessionID string, mfa bool, expiresIn time.Duration, key *jwtkeys.Key) (string, string, error) {
 	// Формируем уникальный идентификатор токена.
 	jti := uuid.NewString()
 
 	claims := dto.AccessToken{
 		UserID: userID,
+		Role:   role,
+		Sid:    sessionID,
 		Jti:    jti,
+		MFA:    mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -25,11 +29,8 @@ func GenerateAccessToken(userID int, expiresIn time.Duration, secret []byte) (st
 		},
 	}
 
-	// Зашиваем данные в токен и указываем дальнейший метод хеша.
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Подписываем токен с использованием секретного ключа и хэшируем.
-	tokenString, err := token.SignedString(secret)
+	// Подписываем токен, в заголовке указывается kid ключа.
+	tokenString, err := key.Sign(claims)
 	return tokenString, jti, err // Возвращаем сформированный токен в виде строки...,
 	//уникальный идентификатор токена и ошибку, если есть.
 }
@@ -37,13 +38,21 @@ func GenerateAccessToken(userID int, expiresIn time.Duration, secret []byte) (st
 // GenerateRefreshToken - генерирует Refresh token, применяя метод хеша sha256.
 // Возвращает 2 переменные - refresh token и refresh token, только хэшированный.
 func GenerateRefreshToken() (string, string) {
+	return GenerateOpaqueToken()
+}
+
+// GenerateOpaqueToken - генерирует случайный непрозрачный токен (refresh, сброс пароля и т.д.).
+// Возвращает токен для пользователя и его SHA-256 хэш для хранения в БД.
+func GenerateOpaqueToken() (string, string) {
 	// генерируем случайный UUID в качестве токена
 	token := uuid.NewString()
 
-	// Создаем SHA-256 хеш от токена для безопасного хранения в БД
-	hash := sha256.Sum256([]byte(token))
-	hashToken := hex.EncodeToString(hash[:])
-
 	// Возвращаем токен и хеш от токена. Первый - пользователю, второй - в БД.
-	return token, hashToken
+	return token, HashToken(token)
+}
+
+// HashToken - считает SHA-256 хэш токена в hex, в таком виде токены хранятся в БД.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
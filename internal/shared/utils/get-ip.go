@@ -1,15 +1,97 @@
 package utils
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 )
 
-// GetIPAddress - Вспомогательная функция для получения IP.
+// clientIPKey - неэкспортируемый тип ключа, чтобы не пересекаться с ключами других пакетов.
+type clientIPKey struct{}
+
+// ContextWithClientIP - кладёт IP клиента, определённый middleware ClientIP, в контекст запроса.
+func ContextWithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// GetIPAddress - Вспомогательная функция для получения IP. Возвращает адрес, определённый middleware ClientIP,
+// а без него - адрес TCP соединения. Заголовкам клиента здесь не доверяем: их подделка обходила бы лимиты по IP.
 func GetIPAddress(r *http.Request) string {
-	// Пробуем получить IP из заголовков (если за прокси)
-	if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
-		return strings.Split(ip, ",")[0]
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return RemoteIP(r)
+}
+
+// RemoteIP - адрес TCP соединения без порта. IPv6 - без квадратных скобок.
+func RemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr.Unmap().String()
+	}
+	return host
+}
+
+// ClientIP - IP клиента с учётом доверенных прокси. X-Forwarded-For учитывается, только если соединение пришло
+// от доверенного прокси, и разбирается справа налево: каждый доверенный прокси дописывает адрес, от которого получил запрос,
+// поэтому клиент - первый адрес, не принадлежащий доверенным прокси. Левее него значения задаёт сам клиент.
+func ClientIP(r *http.Request, trusted []netip.Prefix) string {
+	ip := RemoteIP(r)
+	if !isTrusted(ip, trusted) {
+		return ip
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// Непонятная запись - дальше цепочке не верим и считаем клиентом последний проверенный адрес.
+			return ip
+		}
+		ip = addr.Unmap().String()
+		if !isTrusted(ip, trusted) {
+			return ip
+		}
+	}
+
+	// Все адреса цепочки - доверенные прокси: клиентом считаем самый левый.
+	return ip
+}
+
+// ParseTrustedProxies - разбирает список доверенных прокси: IP адреса или подсети в нотации CIDR.
+func ParseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный адрес доверенного прокси %q", proxy)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// isTrusted - адрес принадлежит одному из доверенных прокси.
+func isTrusted(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
 	}
-	return strings.Split(r.RemoteAddr, ":")[0]
+	return false
 }
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "fd00::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		want       string
+	}{
+		{name: "IPv4 без прокси", remoteAddr: "203.0.113.7:51234", want: "203.0.113.7"},
+		{name: "IPv6 без прокси", remoteAddr: "[2001:db8::7]:51234", want: "2001:db8::7"},
+		{name: "IPv4 в IPv6", remoteAddr: "[::ffff:203.0.113.7]:51234", want: "203.0.113.7"},
+		{name: "X-Forwarded-For от недоверенного адреса игнорируется", remoteAddr: "203.0.113.7:1", xff: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "клиент за доверенным прокси", remoteAddr: "10.0.0.2:1", xff: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "подделанное начало цепочки не учитывается", remoteAddr: "10.0.0.2:1", xff: []string{"1.2.3.4, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "цепочка доверенных прокси", remoteAddr: "10.0.0.2:1", xff: []string{"198.51.100.1, 10.1.1.1"}, want: "198.51.100.1"},
+		{name: "несколько заголовков", remoteAddr: "10.0.0.2:1", xff: []string{"1.2.3.4", "198.51.100.1"}, want: "198.51.100.1"},
+		{name: "доверенный IPv6 прокси", remoteAddr: "[fd00::1]:1", xff: []string{"2001:db8::9"}, want: "2001:db8::9"},
+		{name: "мусор в цепочке", remoteAddr: "10.0.0.2:1", xff: []string{"198.51.100.1, garbage"}, want: "10.0.0.2"},
+		{name: "доверенный прокси без заголовка", remoteAddr: "10.0.0.2:1", want: "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			if got := ClientIP(r, trusted); got != tt.want {
+				t.Errorf("ClientIP() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxiesInvalid(t *testing.T) {
+	if _, err := ParseTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("ожидалась ошибка для некорректной подсети")
+	}
+	if _, err := ParseTrustedProxies([]string{"proxy.local"}); err == nil {
+		t.Error("ожидалась ошибка для имени хоста")
+	}
+}
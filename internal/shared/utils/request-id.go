@@ -0,0 +1,17 @@
+package utils
+
+import "context"
+
+// requestIDKey - неэкспортируемый тип ключа, чтобы не пересекаться с ключами других пакетов.
+type requestIDKey struct{}
+
+// ContextWithRequestID - кладёт идентификатор запроса в контекст.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext - возвращает идентификатор запроса, присвоенный middleware RequestID, или пустую строку.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -2,21 +2,15 @@ package utils
 
 import (
 	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/jwtkeys"
 	"errors"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func ValidateAccessToken(tokenString, secret string) (*dto.AccessToken, error) {
-	// Парсим токен с проверкой подписи
-	token, err := jwt.ParseWithClaims(tokenString, &dto.AccessToken{},
-		func(token *jwt.Token) (interface{}, error) {
-			// Проверяем, что используется ожидаемый алгоритм подписи
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(secret), nil
-		},
-	)
+// ValidateAccessToken - проверяет подпись Access token ключом из набора keys, выбранным по kid, и срок его действия.
+func ValidateAccessToken(tokenString string, keys *jwtkeys.KeySet) (*dto.AccessToken, error) {
+	// Парсим токен с проверкой подписи. Алгоритм токена должен совпадать с алгоритмом ключа.
+	token, err := jwt.ParseWithClaims(tokenString, &dto.AccessToken{}, keys.Keyfunc)
 	if err != nil {
 		return nil, err
 	}
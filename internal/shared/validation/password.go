@@ -0,0 +1,21 @@
+package validation
+
+import (
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/passwords"
+	"errors"
+	"reflect"
+)
+
+// password - пароль соответствует формату из политики паролей: длина и обязательные классы символов.
+// Нарушение сообщается кодом password_<что не так>, чтобы клиент мог подсказать, что исправить.
+// Проверки, которым нужен пользователь или БД, выполняет сервис через passwords.Policy.
+func password(value reflect.Value, _ string) (string, string, bool) {
+	err := passwords.CheckFormat(config.PasswordConfig(), value.String())
+
+	var violation *passwords.Violation
+	if errors.As(err, &violation) {
+		return violation.Rule, violation.Param, false
+	}
+	return "password", "", true
+}
@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ruleFunc - проверка значения по правилу с параметром из тега. Возвращает код нарушения и его параметр,
+// они могут уточнять правило: min у строки нарушается как min_length, у списка - как min_items.
+type ruleFunc func(value reflect.Value, param string) (code, codeParam string, ok bool)
+
+// rules - правила, доступные в теге validate.
+var rules = map[string]ruleFunc{
+	"required": required,
+	"email":    email,
+	"password": password,
+	"min":      minLength,
+	"max":      maxLength,
+	"gt":       greaterThan,
+	"oneof":    oneOf,
+}
+
+// emailRegex - упрощённая проверка адреса: локальная часть, @ и домен с зоной не короче двух букв.
+// Компилируется один раз и не использует lookahead, которого нет в RE2.
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+
+// maxEmailLength - ограничение длины адреса из RFC 5321.
+const maxEmailLength = 254
+
+// required - значение заполнено: строка не из одних пробелов, список не пуст, число не ноль, указатель не nil.
+func required(value reflect.Value, _ string) (string, string, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return "required", "", strings.TrimSpace(value.String()) != ""
+	case reflect.Slice, reflect.Map:
+		return "required", "", value.Len() > 0
+	default:
+		return "required", "", !value.IsZero()
+	}
+}
+
+// email - строка похожа на адрес электронной почты.
+func email(value reflect.Value, _ string) (string, string, bool) {
+	address := value.String()
+	return "email", "", len(address) <= maxEmailLength && emailRegex.MatchString(address)
+}
+
+// minLength - не меньше param символов в строке или элементов в списке.
+func minLength(value reflect.Value, param string) (string, string, bool) {
+	limit := intParam("min", param)
+	if value.Kind() == reflect.String {
+		return "min_length", param, utf8.RuneCountInString(value.String()) >= limit
+	}
+	return "min_items", param, value.Len() >= limit
+}
+
+// maxLength - не больше param символов в строке или элементов в списке.
+func maxLength(value reflect.Value, param string) (string, string, bool) {
+	limit := intParam("max", param)
+	if value.Kind() == reflect.String {
+		return "max_length", param, utf8.RuneCountInString(value.String()) <= limit
+	}
+	return "max_items", param, value.Len() <= limit
+}
+
+// greaterThan - число больше param.
+func greaterThan(value reflect.Value, param string) (string, string, bool) {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validation: некорректный параметр gt=%s", param))
+	}
+
+	switch {
+	case value.CanInt():
+		return "gt", param, float64(value.Int()) > limit
+	case value.CanUint():
+		return "gt", param, float64(value.Uint()) > limit
+	case value.CanFloat():
+		return "gt", param, value.Float() > limit
+	}
+	panic(fmt.Sprintf("validation: правило gt неприменимо к %s", value.Type()))
+}
+
+// oneOf - строка равна одному из значений param, перечисленных через пробел.
+func oneOf(value reflect.Value, param string) (string, string, bool) {
+	allowed := strings.Fields(param)
+	return "oneof", strings.Join(allowed, ", "), slices.Contains(allowed, value.String())
+}
+
+// intParam - целочисленный параметр правила.
+func intParam(rule, param string) int {
+	value, err := strconv.Atoi(param)
+	if err != nil || value < 0 {
+		panic(fmt.Sprintf("validation: некорректный параметр %s=%s", rule, param))
+	}
+	return value
+}
@@ -0,0 +1,144 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrInvalid - запрос не прошёл проверку. Подробности по полям - в Errors.
+var ErrInvalid = errors.New("запрос содержит некорректные поля")
+
+// FieldError - ошибка одного поля запроса. Rule - код нарушенного правила, по нему клиент и каталог сообщений
+// понимают, что не так, Param - параметр правила (например минимальная длина), если он есть.
+type FieldError struct {
+	Field string
+	Rule  string
+	Param string
+}
+
+// Errors - ошибки всех некорректных полей запроса, по одной на поле, в порядке объявления полей.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for _, fe := range e {
+		fields = append(fields, fe.Field+": "+fe.Rule)
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalid, strings.Join(fields, ", "))
+}
+
+// Unwrap - позволяет распознавать ошибку проверки через errors.Is(err, ErrInvalid).
+func (e Errors) Unwrap() error {
+	return ErrInvalid
+}
+
+// Struct - проверяет структуру (или указатель на неё) по тегам validate, например:
+//
+//	Email string `json:"email" validate:"required,email"`
+//
+// Правила перечисляются через запятую и проверяются по порядку до первого нарушенного. Все правила, кроме required,
+// пропускают пустые значения, поэтому необязательные поля проверяются, только если заполнены.
+// Имя поля в ошибке берётся из тега json. Возвращает Errors или nil.
+func Struct(v any) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: ожидается структура, получен %s", value.Type()))
+	}
+
+	var errs Errors
+	for _, field := range fieldsOf(value.Type()) {
+		if fe, ok := field.check(value.Field(field.index)); !ok {
+			errs = append(errs, fe)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// structField - разобранные теги одного поля структуры.
+type structField struct {
+	index int
+	name  string
+	rules []boundRule
+}
+
+// boundRule - правило с параметром из тега.
+type boundRule struct {
+	name  string
+	param string
+	check ruleFunc
+}
+
+// check - проверяет значение поля по его правилам.
+func (f structField) check(value reflect.Value) (FieldError, bool) {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			break
+		}
+		value = value.Elem()
+	}
+
+	for _, rule := range f.rules {
+		if rule.name != "required" && value.IsZero() {
+			return FieldError{}, true
+		}
+		if code, param, ok := rule.check(value, rule.param); !ok {
+			return FieldError{Field: f.name, Rule: code, Param: param}, false
+		}
+	}
+	return FieldError{}, true
+}
+
+// fieldsCache - разобранные теги по типам: теги разбираются один раз на тип, а не на каждый запрос.
+var fieldsCache sync.Map // map[reflect.Type][]structField
+
+// fieldsOf - поля типа с тегом validate. Ошибка в теге - ошибка программиста, поэтому паника, как у regexp.MustCompile.
+func fieldsOf(t reflect.Type) []structField {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		field := structField{index: i, name: jsonName(sf)}
+		for _, raw := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(strings.TrimSpace(raw), "=")
+			check, ok := rules[name]
+			if !ok {
+				panic(fmt.Sprintf("validation: неизвестное правило %q у поля %s.%s", name, t.Name(), sf.Name))
+			}
+			field.rules = append(field.rules, boundRule{name: name, param: param, check: check})
+		}
+		fields = append(fields, field)
+	}
+
+	fieldsCache.Store(t, fields)
+	return fields
+}
+
+// jsonName - имя поля в JSON запроса, под которым его знает клиент.
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
@@ -0,0 +1,206 @@
+package validation
+
+import (
+	"DBManager/internal/shared/config"
+	cfgdto "DBManager/internal/shared/dto/config"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// Правило password читает политику паролей из конфигурации, поэтому она загружается со значениями по умолчанию.
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if _, err := config.Load(fs, []string{
+		"-postgres-connect-string", "postgres://test",
+		"-redis-addr", "localhost:6379",
+		"-redis-pass", "test",
+		"-access-secret", "test-access-secret-test-access-secret",
+		"-mailer-driver", cfgdto.MailerDriverMemory,
+	}); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+// thousandths - целое число с собственным типом, как dto.Quantity.
+type thousandths int64
+
+type testRequest struct {
+	Email    string      `json:"email" validate:"required,email"`
+	Password string      `json:"password,omitempty" validate:"password"`
+	Name     string      `json:"name" validate:"min=2,max=5"`
+	Tags     []string    `json:"tags" validate:"max=2"`
+	Type     string      `json:"type" validate:"oneof=receipt issue"`
+	ItemID   *int        `json:"item_id" validate:"gt=0"`
+	Quantity thousandths `json:"quantity" validate:"required,gt=0"`
+	Price    float64     `json:"price" validate:"gt=0.5"`
+	Comment  string      `validate:"max=3"`
+	Ignored  string      `json:"ignored"`
+}
+
+// validRequest - запрос, проходящий все правила.
+func validRequest() testRequest {
+	return testRequest{Email: "user@example.com", Quantity: 1, Ignored: "не проверяется"}
+}
+
+func TestStruct(t *testing.T) {
+	zero, one := 0, 1
+
+	tests := []struct {
+		name   string
+		modify func(r *testRequest)
+		want   Errors
+	}{
+		{name: "корректный", modify: func(*testRequest) {}},
+		{name: "необязательные поля заполнены корректно", modify: func(r *testRequest) {
+			r.Password, r.Name, r.Tags, r.Type, r.ItemID, r.Price, r.Comment = "abcdef1!", "Аня", []string{"a", "b"}, "issue", &one, 0.75, "abc"
+		}},
+		{
+			name:   "обязательные поля пусты",
+			modify: func(r *testRequest) { r.Email, r.Quantity = "   ", 0 },
+			want:   Errors{{Field: "email", Rule: "required"}, {Field: "quantity", Rule: "required"}},
+		},
+		{
+			name:   "правила проверяются до первого нарушенного",
+			modify: func(r *testRequest) { r.Email = "not-an-email" },
+			want:   Errors{{Field: "email", Rule: "email"}},
+		},
+		{
+			name:   "длина строки в символах",
+			modify: func(r *testRequest) { r.Name = "Ё" },
+			want:   Errors{{Field: "name", Rule: "min_length", Param: "2"}},
+		},
+		{
+			name:   "слишком длинная строка",
+			modify: func(r *testRequest) { r.Name = "abcdef" },
+			want:   Errors{{Field: "name", Rule: "max_length", Param: "5"}},
+		},
+		{
+			name:   "слишком много элементов",
+			modify: func(r *testRequest) { r.Tags = []string{"a", "b", "c"} },
+			want:   Errors{{Field: "tags", Rule: "max_items", Param: "2"}},
+		},
+		{
+			name:   "значение не из списка",
+			modify: func(r *testRequest) { r.Type = " issue" },
+			want:   Errors{{Field: "type", Rule: "oneof", Param: "receipt, issue"}},
+		},
+		{
+			name:   "указатель на ноль проверяется как ноль",
+			modify: func(r *testRequest) { r.ItemID = &zero },
+			want:   nil,
+		},
+		{
+			name:   "отрицательные числа",
+			modify: func(r *testRequest) { m := -1; r.ItemID, r.Quantity, r.Price = &m, -5, 0.25 },
+			want: Errors{
+				{Field: "item_id", Rule: "gt", Param: "0"},
+				{Field: "quantity", Rule: "gt", Param: "0"},
+				{Field: "price", Rule: "gt", Param: "0.5"},
+			},
+		},
+		{
+			name:   "пароль не по политике",
+			modify: func(r *testRequest) { r.Password = "abcdefgh" },
+			want:   Errors{{Field: "password", Rule: "password_digit"}},
+		},
+		{
+			name:   "без тега json - имя поля",
+			modify: func(r *testRequest) { r.Comment = "abcd" },
+			want:   Errors{{Field: "Comment", Rule: "max_length", Param: "3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validRequest()
+			tt.modify(&r)
+
+			err := Struct(&r)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Struct: %v", err)
+				}
+				return
+			}
+
+			var got Errors
+			if !errors.As(err, &got) || !errors.Is(err, ErrInvalid) {
+				t.Fatalf("ошибка %v, ожидались ошибки полей", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ошибки %+v, ожидались %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructArguments(t *testing.T) {
+	r := validRequest()
+	pr := &r
+	if err := Struct(r); err != nil {
+		t.Fatalf("структура по значению: %v", err)
+	}
+	if err := Struct(&pr); err != nil {
+		t.Fatalf("указатель на указатель: %v", err)
+	}
+	if err := Struct((*testRequest)(nil)); err != nil {
+		t.Fatalf("nil указатель: %v", err)
+	}
+}
+
+func TestStructPanicsOnBadTag(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{name: "неизвестное правило", v: &struct {
+			Name string `validate:"requried"`
+		}{}},
+		{name: "некорректный параметр", v: &struct {
+			Name string `validate:"min=abc"`
+		}{Name: "x"}},
+		{name: "не структура", v: "строка"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("ожидалась паника: ошибка в теге - ошибка программиста")
+				}
+			}()
+			_ = Struct(tt.v)
+		})
+	}
+}
+
+func TestEmail(t *testing.T) {
+	tests := map[string]bool{
+		"user@example.com":         true,
+		"first.last+tag@sub.ex.io": true,
+		"user@localhost":           false,
+		"user@example.c":           false,
+		"@example.com":             false,
+		"user example@example.com": false,
+		"user@exa mple.com":        false,
+	}
+
+	for address, want := range tests {
+		if _, _, ok := email(reflect.ValueOf(address), ""); ok != want {
+			t.Errorf("email(%q) = %v, ожидалось %v", address, ok, want)
+		}
+	}
+
+	long := strings.Repeat("a", maxEmailLength) + "@example.com"
+	if _, _, ok := email(reflect.ValueOf(long), ""); ok {
+		t.Error("адрес длиннее 254 символов должен отклоняться")
+	}
+}
@@ -0,0 +1,76 @@
+package transport
+
+import (
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/utils"
+	"net/http"
+	"strconv"
+)
+
+func (c *Controller) CreateAPIKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		var req dto.CreateAPIKeyRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		key, err := c.IAuth.CreateAPIKey(r.Context(), claims, &req)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, key)
+	}
+}
+
+// ListAPIKeys - ключи текущего пользователя, администратор может указать ?user_id=.
+func (c *Controller) ListAPIKeys() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		userID, _ := strconv.Atoi(r.URL.Query().Get("user_id"))
+
+		keys, err := c.IAuth.ListAPIKeys(r.Context(), claims, userID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, keys)
+	}
+}
+
+func (c *Controller) RevokeAPIKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		var req dto.RevokeAPIKeyRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if err := c.IAuth.RevokeAPIKey(r.Context(), claims, req.ID); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,16 @@
+package transport
+
+import (
+	"DBManager/internal/shared/utils"
+	"net/http"
+	"net/netip"
+)
+
+// ClientIP - middleware, определяющий IP клиента один раз на запрос: X-Forwarded-For учитывается только
+// от доверенных прокси (TRUSTED_PROXIES). По этому адресу считаются лимиты запросов и попыток входа.
+func (c *Controller) ClientIP(trusted []netip.Prefix, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ip := utils.ClientIP(r, trusted)
+		next.ServeHTTP(w, r.WithContext(utils.ContextWithClientIP(r.Context(), ip)))
+	}
+}
@@ -3,30 +3,32 @@ package transport
 import (
 	"DBManager/internal/service"
 	errors2 "DBManager/internal/service/errors"
-	"DBManager/internal/shared/config"
 	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/ratelimit"
+	"DBManager/internal/shared/rbac"
 	"DBManager/internal/shared/utils"
-	"encoding/json"
 	"errors"
-	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
 type Controller struct {
 	service.IAuth
+	service.IManager
+	limiter ratelimit.Limiter
 }
 
-func NewController(auth service.IAuth) *Controller {
-	return &Controller{auth}
+func NewController(auth service.IAuth, manager service.IManager, limiter ratelimit.Limiter) *Controller {
+	return &Controller{auth, manager, limiter}
 }
 
 func (c *Controller) SignIn() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Декодируем JSON в структуру creds.
 		var creds dto.SignInRequest
-		if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
-			http.Error(w, "Invalid request", http.StatusBadRequest)
+		if err := decodeJSON(r, &creds); err != nil {
+			writeError(w, r, err)
 			return
 		}
 
@@ -35,24 +37,26 @@ func (c *Controller) SignIn() http.HandlerFunc {
 		ipAddress := utils.GetIPAddress(r)
 
 		// Аутентифицируем пользователя - проверяем пароль и логин, создаём токены.
-		tokens, err := c.IAuth.Authentication(r.Context(), &creds, deviceInfo, ipAddress)
+		tokens, challenge, err := c.IAuth.Authentication(r.Context(), &creds, deviceInfo, ipAddress)
 		if err != nil {
+			// Не сообщаем, что именно неверно, - иначе по ответу можно проверять, зарегистрирован ли email.
 			if errors.Is(err, errors2.PasswordWrong) || errors.Is(err, errors2.UserNotExist) {
-				http.Error(w, "Неверно введен Email или пароль.", http.StatusUnauthorized)
+				err = errInvalidCredentials
 			}
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			writeError(w, r, err)
+			return
+		}
+
+		// Подключена вторая ступень - токены выдаст VerifyMFA.
+		if challenge != nil {
+			writeJSON(w, http.StatusOK, challenge)
 			return
 		}
 
 		// Устанавливаем Refresh токен в куки.
 		utils.SetRefreshTokenCookie(w, tokens.RefreshToken, false)
 
-		// Устанавливаем заголовок и возвращаем в теле токены.
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(tokens); err != nil {
-			http.Error(w, "Не удалось серелизовать объект в JSON", http.StatusInternalServerError)
-			return
-		}
+		writeJSON(w, http.StatusOK, tokens)
 	}
 }
 
@@ -60,8 +64,8 @@ func (c *Controller) SignUp() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Декодируем JSON в структуру creds.
 		var creds dto.SignUpRequest
-		if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
-			http.Error(w, "Invalid request", http.StatusBadRequest)
+		if err := decodeJSON(r, &creds); err != nil {
+			writeError(w, r, err)
 			return
 		}
 
@@ -69,63 +73,41 @@ func (c *Controller) SignUp() http.HandlerFunc {
 		deviceInfo := r.Header.Get("X-Device-Info")
 		ipAddress := utils.GetIPAddress(r)
 
-		// Не забыть отозвать старый токен...
-
 		// Регистрируем пользователя - добавляем в бд, создаём токены.
 		tokens, err := c.IAuth.Registration(r.Context(), &creds, deviceInfo, ipAddress)
 		if err != nil {
-			if errors.Is(err, errors2.PasswordWrong) || errors.Is(err, errors2.UserNotExist) {
-				http.Error(w, "Неверно введен Email или пароль.", http.StatusUnauthorized)
+			// Пользователь создан, но войти сможет только после подтверждения email.
+			if errors.Is(err, errors2.ErrEmailNotVerified) {
+				writeJSON(w, http.StatusAccepted, map[string]string{"message": localize(r, "verification_sent")})
+				return
 			}
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			writeError(w, r, err)
 			return
 		}
 
 		// Устанавливаем Refresh токен в куки.
 		utils.SetRefreshTokenCookie(w, tokens.RefreshToken, false)
 
-		// Устанавливаем заголовок и возвращаем в теле токены.
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(map[string]string{
-			"access_token": tokens.AccessToken,
-			// Refresh token не возвращаем, так как он в куках
-		}); err != nil {
-			slog.Error("JSON encode error: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		// Refresh token не возвращаем, так как он в куках.
+		writeJSON(w, http.StatusOK, map[string]string{"access_token": tokens.AccessToken})
 	}
 }
 
 func (c *Controller) LogOut() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Получаем данные из заголовка Авторизации.
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "authorization header required", http.StatusUnauthorized)
-			return
-		}
-
-		// Убираем байты слова "Bearer ", что получить чистую строку с Access Token
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			http.Error(w, "invalid authorization header format", http.StatusUnauthorized)
-			return
-		}
-
-		// Валидируем Access Token, расшифровываем его секретным ключом и сравниваем данные.
-		claims, err := utils.ValidateAccessToken(tokenString, config.TokenConfig().AccessSecret)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusUnauthorized)
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
 			return
 		}
 
 		if err := c.IAuth.LogOut(r.Context(), claims); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, r, err)
 			return
 		}
 
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("Successfully logged out"))
+		_, _ = w.Write([]byte(localize(r, "logged_out")))
 	}
 }
 
@@ -133,73 +115,245 @@ func (c *Controller) RefreshTokens() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		cookie, err := r.Cookie("refresh_token")
 		if err != nil {
-			http.Error(w, "Refresh token not found in cookies", http.StatusBadRequest)
+			writeError(w, r, errRefreshTokenMissing)
 			return
 		}
 
 		refreshToken := cookie.Value
 
-		tokens, err := c.IAuth.RefreshTokens(r.Context(), refreshToken)
+		tokens, err := c.IAuth.RefreshTokens(r.Context(), refreshToken, utils.GetIPAddress(r))
 		if err != nil {
-			if errors.Is(err, errors2.ErrRefreshTokenExpired) || errors.Is(err, errors2.ErrRefreshTokenRevoked) {
-				http.Error(w, err.Error(), http.StatusUnauthorized)
-				return
-			}
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			slog.Error("RefreshTokens error: ", err)
+			writeError(w, r, err)
 			return
 		}
 
 		// Устанавливаем Refresh токен в куки.
 		utils.SetRefreshTokenCookie(w, tokens.RefreshToken, false)
 
-		// Устанавливаем заголовок и возвращаем в теле токены.
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(map[string]string{
-			"access_token": tokens.AccessToken,
-			// Refresh token не возвращаем, так как он в куках
-		}); err != nil {
-			slog.Error("JSON encode error: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		// Refresh token не возвращаем, так как он в куках.
+		writeJSON(w, http.StatusOK, map[string]string{"access_token": tokens.AccessToken})
+	}
+}
+
+func (c *Controller) VerifyEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.VerifyEmailRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if err := c.IAuth.VerifyEmail(r.Context(), req.Token); err != nil {
+			writeError(w, r, err)
+			return
 		}
+
+		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
+func (c *Controller) ResendVerification() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.ResendVerificationRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if err := c.IAuth.ResendVerification(r.Context(), req.Email); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (c *Controller) ForgotPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.ForgotPasswordRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		// Ответ одинаковый для существующих и несуществующих email.
+		if err := c.IAuth.ForgotPassword(r.Context(), req.Email); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (c *Controller) ResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.ResetPasswordRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if err := c.IAuth.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (c *Controller) ChangePassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		var req dto.ChangePasswordRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		deviceInfo := r.Header.Get("X-Device-Info")
+		ipAddress := utils.GetIPAddress(r)
+
+		tokens, err := c.IAuth.ChangePassword(r.Context(), claims, &req, deviceInfo, ipAddress)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		// Устанавливаем новый Refresh токен в куки.
+		utils.SetRefreshTokenCookie(w, tokens.RefreshToken, false)
+
+		writeJSON(w, http.StatusOK, tokens)
+	}
+}
+
+func (c *Controller) ChangeUserRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.ChangeRoleRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if err := c.IAuth.ChangeUserRole(r.Context(), req.UserID, req.Role); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (c *Controller) UnlockUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.UnlockUserRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if err := c.IAuth.UnlockAccount(r.Context(), req.Email); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// setRetryAfter - выставляет заголовок Retry-After, если ошибка сообщает, когда можно повторить запрос.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var retry *errors2.RetryAfter
+	if errors.As(err, &retry) {
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retry.After)))
+	}
+}
+
+// apiKeyHeader - заголовок, в котором интеграции передают API ключ.
+const apiKeyHeader = "X-API-Key"
+
+// Authorization - middleware, пропускающий запрос дальше только с действующим Access Token или API ключом.
+// Данные токена кладутся в контекст запроса и доступны через utils.ClaimsFromContext.
 func (c *Controller) Authorization(next http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		_ = next
+		// Интеграции авторизуются API ключом вместо Access Token.
+		if apiKey := r.Header.Get(apiKeyHeader); apiKey != "" {
+			claims, err := c.IAuth.ValidateAPIKey(r.Context(), apiKey, utils.GetIPAddress(r))
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(utils.ContextWithClaims(r.Context(), claims)))
+			return
+		}
+
 		// Получаем данные из заголовка Авторизации.
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "authorization header required", http.StatusUnauthorized)
+			writeError(w, r, errUnauthorized)
 			return
 		}
 
 		// Убираем байты слова "Bearer ", что получить чистую строку с Access Token
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			http.Error(w, "invalid authorization header format", http.StatusUnauthorized)
+			writeError(w, r, errInvalidAuthHeader)
 			return
 		}
 
-		// Валидируем Access Token, расшифровываем его секретным ключом и сравниваем данные.
-		claims, err := utils.ValidateAccessToken(tokenString, config.TokenConfig().AccessSecret)
+		// Валидируем Access Token и проверяем, что он не был отозван.
+		claims, err := c.IAuth.ValidateAccessToken(r.Context(), tokenString)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusUnauthorized)
+			writeError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(utils.ContextWithClaims(r.Context(), claims)))
+	}
+}
+
+// Require - оборачивает обработчик проверкой, что роль из Access Token имеет указанное право.
+// Должен вызываться за middleware Authorization.
+func (c *Controller) Require(permission rbac.Permission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		if err := service.Authorize(claims, permission); err != nil {
+			writeError(w, r, err)
 			return
 		}
 
-		// Устанавливаем заголовки.
-		w.Header().Set("Content-Type", "application/json")
+		next(w, r)
+	}
+}
+
+// SessionOnly - middleware для действий с учётной записью и сессиями, недоступных по API ключу.
+// Должен вызываться за middleware Authorization.
+func (c *Controller) SessionOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
 
-		// Серелизуем карту с данными в JSON.
-		if err := json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": "protected content",
-			"user_id": claims.UserID,
-			"jti":     claims.Jti,
-		}); err != nil {
-			http.Error(w, "Не удалось серелизовать объект в JSON", http.StatusInternalServerError)
+		if claims.APIKeyID != 0 {
+			writeError(w, r, errors2.ErrSessionRequired)
 			return
 		}
+
+		next(w, r)
 	}
 }
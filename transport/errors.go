@@ -0,0 +1,194 @@
+package transport
+
+import (
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/i18n"
+	"DBManager/internal/shared/oidc"
+	"DBManager/internal/shared/utils"
+	"DBManager/internal/shared/validation"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Ошибки транспортного слоя - то, что отклоняется до обращения к сервисам.
+var (
+	errInvalidRequest      = errors.New("некорректный запрос")
+	errUnauthorized        = errors.New("требуется авторизация")
+	errInvalidAuthHeader   = errors.New("некорректный заголовок Authorization, ожидается Bearer токен")
+	errInvalidCredentials  = errors.New("неверно введен Email или пароль")
+	errRefreshTokenMissing = errors.New("refresh token не найден в куки")
+	errRateLimited         = errors.New("слишком много запросов, повторите позже")
+	errInternal            = errors.New("внутренняя ошибка сервера")
+)
+
+// errorSpec - как ошибка отдаётся клиенту: HTTP статус и стабильный код, по которому клиент её распознаёт.
+// Текст ответа берётся из каталога сообщений по коду на языке запроса, текст сентинела - запасной вариант.
+// err.Error() клиенту не отдаётся: обёрнутые ошибки могут содержать внутренние подробности.
+type errorSpec struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorTable - соответствие ошибок HTTP статусам и кодам. Ошибка ищется через errors.Is сверху вниз,
+// всё, чего нет в таблице, отдаётся как internal_error без подробностей.
+var errorTable = []errorSpec{
+	// Запрос и авторизация.
+	{errInvalidRequest, http.StatusBadRequest, "invalid_request"},
+	{validation.ErrInvalid, http.StatusBadRequest, "validation_failed"},
+	{errUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{errInvalidAuthHeader, http.StatusUnauthorized, "invalid_authorization_header"},
+	{errInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+	{errRefreshTokenMissing, http.StatusUnauthorized, "refresh_token_missing"},
+	{errRateLimited, http.StatusTooManyRequests, "rate_limited"},
+	{errors2.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{errors2.ErrSessionRequired, http.StatusForbidden, "session_required"},
+
+	// Учётная запись.
+	{errors2.UserNotExist, http.StatusNotFound, "user_not_found"},
+	{errors2.PasswordWrong, http.StatusBadRequest, "password_wrong"},
+	{errors2.EmailAlreadyExist, http.StatusConflict, "email_already_exists"},
+	{errors2.ErrInvalidRole, http.StatusBadRequest, "invalid_role"},
+	{errors2.ErrPasswordUnchanged, http.StatusBadRequest, "password_unchanged"},
+	{errors2.ErrEmailNotVerified, http.StatusForbidden, "email_not_verified"},
+	{errors2.ErrTooManyLoginAttempts, http.StatusTooManyRequests, "too_many_login_attempts"},
+	{errors2.ErrAccountLocked, http.StatusLocked, "account_locked"},
+
+	// Токены и сессии.
+	{errors2.ErrRefreshTokenInvalid, http.StatusUnauthorized, "refresh_token_invalid"},
+	{errors2.ErrRefreshTokenRevoked, http.StatusUnauthorized, "refresh_token_revoked"},
+	{errors2.ErrRefreshTokenExpired, http.StatusUnauthorized, "refresh_token_expired"},
+	{errors2.ErrRefreshTokenReused, http.StatusUnauthorized, "refresh_token_reused"},
+	{errors2.ErrAccessTokenInvalid, http.StatusUnauthorized, "access_token_invalid"},
+	{errors2.ErrAccessTokenRevoked, http.StatusUnauthorized, "access_token_revoked"},
+	{errors2.ErrSessionNotFound, http.StatusNotFound, "session_not_found"},
+	{errors2.ErrResetTokenInvalid, http.StatusBadRequest, "reset_token_invalid"},
+	{errors2.ErrVerifyTokenInvalid, http.StatusBadRequest, "verify_token_invalid"},
+
+	// Двухфакторная аутентификация.
+	{errors2.ErrMFAAlreadyEnabled, http.StatusConflict, "mfa_already_enabled"},
+	{errors2.ErrMFANotEnabled, http.StatusConflict, "mfa_not_enabled"},
+	{errors2.ErrMFACodeInvalid, http.StatusBadRequest, "mfa_code_invalid"},
+	{errors2.ErrMFAChallengeInvalid, http.StatusUnauthorized, "mfa_challenge_invalid"},
+	{errors2.ErrMFARequired, http.StatusForbidden, "mfa_required"},
+
+	// API ключи.
+	{errors2.ErrAPIKeyInvalid, http.StatusUnauthorized, "api_key_invalid"},
+	{errors2.ErrAPIKeyNotFound, http.StatusNotFound, "api_key_not_found"},
+	{errors2.ErrInvalidAPIKeyFormat, http.StatusBadRequest, "invalid_api_key"},
+	{errors2.ErrAPIKeyScopeNotAllowed, http.StatusBadRequest, "api_key_scope_not_allowed"},
+
+	// Вход через внешнего провайдера.
+	{errors2.ErrOIDCDisabled, http.StatusNotFound, "oidc_disabled"},
+	{errors2.ErrOIDCStateInvalid, http.StatusBadRequest, "oidc_state_invalid"},
+	{errors2.ErrOIDCLoginFailed, http.StatusUnauthorized, "oidc_login_failed"},
+	{errors2.ErrOIDCEmailNotVerified, http.StatusForbidden, "oidc_email_not_verified"},
+	{errors2.ErrOIDCUserNotFound, http.StatusForbidden, "oidc_user_not_found"},
+	{oidc.ErrDiscovery, http.StatusBadGateway, "oidc_provider_unavailable"},
+	{oidc.ErrPKCEUnsupported, http.StatusBadGateway, "oidc_provider_unavailable"},
+
+	// Каталог товаров.
+	{errors2.ErrItemNotFound, http.StatusNotFound, "item_not_found"},
+	{errors2.ErrSKUAlreadyExist, http.StatusConflict, "sku_already_exists"},
+	{errors2.ErrInvalidItemFormat, http.StatusBadRequest, "invalid_item"},
+	{errors2.ErrItemInUse, http.StatusConflict, "item_in_use"},
+
+	// Склады и места хранения.
+	{errors2.ErrWarehouseNotFound, http.StatusNotFound, "warehouse_not_found"},
+	{errors2.ErrLocationNotFound, http.StatusNotFound, "location_not_found"},
+	{errors2.ErrWarehouseCodeAlreadyExist, http.StatusConflict, "warehouse_code_already_exists"},
+	{errors2.ErrLocationCodeAlreadyExist, http.StatusConflict, "location_code_already_exists"},
+	{errors2.ErrInvalidWarehouseFormat, http.StatusBadRequest, "invalid_warehouse"},
+	{errors2.ErrInvalidLocationFormat, http.StatusBadRequest, "invalid_location"},
+
+	// Движения товара.
+	{errors2.ErrInvalidMovementFormat, http.StatusBadRequest, "invalid_movement"},
+	{errors2.ErrInsufficientStock, http.StatusConflict, "insufficient_stock"},
+}
+
+// internalErrorSpec - ответ на любую ошибку не из таблицы.
+var internalErrorSpec = errorSpec{errInternal, http.StatusInternalServerError, "internal_error"}
+
+// lookupError - находит описание ошибки в таблице.
+func lookupError(err error) errorSpec {
+	for _, spec := range errorTable {
+		if errors.Is(err, spec.err) {
+			return spec
+		}
+	}
+	return internalErrorSpec
+}
+
+// detailedError - ошибка с дополнительными данными для поля details ответа.
+type detailedError struct {
+	err     error
+	details any
+}
+
+func (e *detailedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *detailedError) Unwrap() error {
+	return e.err
+}
+
+// withDetails - прикладывает к ошибке данные, которые клиент получит в поле details.
+func withDetails(err error, details any) error {
+	return &detailedError{err: err, details: details}
+}
+
+// invalidParam - некорректный параметр запроса name.
+func invalidParam(name string) error {
+	return withDetails(errInvalidRequest, map[string]string{"param": name})
+}
+
+// writeError - отвечает на ошибку в едином формате dto.ErrorResponse на языке запроса. Ошибки вне таблицы
+// пишутся в лог вместе с идентификатором запроса, клиент получает только этот идентификатор.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	spec := lookupError(err)
+	requestID := utils.RequestIDFromContext(r.Context())
+	lang := i18n.LangFromContext(r.Context())
+
+	if spec.status >= http.StatusInternalServerError {
+		slog.Error("Ошибка обработки запроса", "method", r.Method, "path", r.URL.Path, "request_id", requestID, "error", err)
+	}
+
+	var details any
+	var fields validation.Errors
+	var detailed *detailedError
+	var retry *errors2.RetryAfter
+	switch {
+	case errors.As(err, &fields):
+		details = fieldErrors(lang, fields)
+	case errors.As(err, &detailed):
+		details = detailed.details
+	case errors.As(err, &retry):
+		details = map[string]int{"retry_after": ceilSeconds(retry.After)}
+	}
+	setRetryAfter(w, err)
+
+	writeJSON(w, spec.status, dto.ErrorResponse{
+		Code:      spec.code,
+		Message:   i18n.Message(lang, spec.code, spec.err.Error()),
+		Details:   details,
+		RequestID: requestID,
+	})
+}
+
+// fieldErrors - ошибки полей запроса с описаниями на языке lang. Описание правила берётся из каталога
+// по коду validation.<правило>, параметр правила подставляется в него.
+func fieldErrors(lang i18n.Lang, errs validation.Errors) dto.FieldErrors {
+	fields := make([]dto.FieldError, 0, len(errs))
+	for _, fe := range errs {
+		message := i18n.Message(lang, "validation."+fe.Rule, fe.Rule)
+		if fe.Param != "" {
+			message = strings.ReplaceAll(message, "{param}", fe.Param)
+		}
+		fields = append(fields, dto.FieldError{Field: fe.Field, Code: fe.Rule, Param: fe.Param, Message: message})
+	}
+	return dto.FieldErrors{Fields: fields}
+}
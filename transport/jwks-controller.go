@@ -0,0 +1,14 @@
+package transport
+
+import (
+	"net/http"
+)
+
+// JWKS - отдаёт публичные ключи подписи Access токенов. Потребители кэшируют ответ,
+// поэтому новый ключ должен появиться в наборе заранее, до начала подписи им.
+func (c *Controller) JWKS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		writeJSON(w, http.StatusOK, c.IAuth.JWKS())
+	}
+}
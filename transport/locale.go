@@ -0,0 +1,23 @@
+package transport
+
+import (
+	"DBManager/internal/shared/i18n"
+	"net/http"
+)
+
+// Locale - middleware, выбирающий язык ответа по заголовку Accept-Language. Язык попадает в контекст запроса,
+// по нему переводятся сообщения ошибок. Vary нужен, чтобы кэш не отдал клиенту ответ на чужом языке.
+func (c *Controller) Locale(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lang := i18n.Negotiate(r.Header.Get("Accept-Language"))
+
+		w.Header().Set("Content-Language", string(lang))
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(i18n.ContextWithLang(r.Context(), lang)))
+	}
+}
+
+// localize - сообщение с кодом code на языке запроса.
+func localize(r *http.Request, code string) string {
+	return i18n.Message(i18n.LangFromContext(r.Context()), code, code)
+}
@@ -0,0 +1,122 @@
+package transport
+
+import (
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/validation"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func (c *Controller) CreateItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.CreateItemRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		item, err := c.IManager.CreateItem(r.Context(), &req)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, item)
+	}
+}
+
+func (c *Controller) GetItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemID, err := strconv.Atoi(r.URL.Query().Get("id"))
+		if err != nil {
+			writeError(w, r, invalidParam("id"))
+			return
+		}
+
+		item, err := c.IManager.GetItem(r.Context(), itemID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, item)
+	}
+}
+
+func (c *Controller) ListItems() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		// Параметры пагинации необязательны, некорректные значения считаем нулём.
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		offset, _ := strconv.Atoi(query.Get("offset"))
+
+		items, err := c.IManager.ListItems(r.Context(), &dto.ItemFilter{
+			Category: query.Get("category"),
+			Search:   query.Get("search"),
+			Limit:    limit,
+			Offset:   offset,
+		})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, items)
+	}
+}
+
+func (c *Controller) UpdateItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.UpdateItemRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		item, err := c.IManager.UpdateItem(r.Context(), &req)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, item)
+	}
+}
+
+func (c *Controller) DeleteItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemID, err := strconv.Atoi(r.URL.Query().Get("id"))
+		if err != nil {
+			writeError(w, r, invalidParam("id"))
+			return
+		}
+
+		if err := c.IManager.DeleteItem(r.Context(), itemID); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// decodeJSON - читает тело запроса в v и проверяет его по тегам validate. Некорректный JSON - errInvalidRequest,
+// некорректные поля - validation.Errors, которые writeError отдаёт с подробностями по каждому полю.
+func decodeJSON(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errInvalidRequest
+	}
+	return validation.Struct(v)
+}
+
+// writeJSON - устанавливает заголовок, статус и сериализует объект в тело ответа.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("JSON encode error", "error", err)
+	}
+}
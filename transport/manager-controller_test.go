@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"DBManager/internal/repository"
+	"DBManager/internal/service"
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/i18n"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeManagerRepo - репозиторий, удаление товара в котором завершается ошибкой deleteErr.
+// Остальные методы в этих тестах не вызываются.
+type fakeManagerRepo struct {
+	service.IManagerRepository
+
+	deleteErr error
+}
+
+func (r *fakeManagerRepo) DeleteItem(context.Context, int) error {
+	return r.deleteErr
+}
+
+func TestDeleteItem(t *testing.T) {
+	tests := []struct {
+		name       string
+		deleteErr  error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "удалён", wantStatus: http.StatusNoContent},
+		{name: "не найден", deleteErr: repository.RecordNotFound, wantStatus: http.StatusNotFound, wantCode: "item_not_found"},
+		{name: "есть остатки или движения", deleteErr: repository.RecordInUse, wantStatus: http.StatusConflict, wantCode: "item_in_use"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{IManager: service.NewManager(&fakeManagerRepo{deleteErr: tt.deleteErr})}
+
+			r := httptest.NewRequest(http.MethodDelete, "/DeleteItem?id=1", nil)
+			r = r.WithContext(i18n.ContextWithLang(r.Context(), i18n.EN))
+			w := httptest.NewRecorder()
+			c.DeleteItem().ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("статус %d, ожидался %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+
+			var resp dto.ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Fatalf("код %q, ожидался %q", resp.Code, tt.wantCode)
+			}
+			if resp.Message != i18n.Message(i18n.EN, tt.wantCode, "") || resp.Message == "" {
+				t.Fatalf("сообщение %q не из перевода", resp.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package transport
+
+import (
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/utils"
+	"net/http"
+)
+
+// VerifyMFA - второй шаг входа: обмен вызова и кода на пару токенов.
+func (c *Controller) VerifyMFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.VerifyMFARequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		deviceInfo := r.Header.Get("X-Device-Info")
+		ipAddress := utils.GetIPAddress(r)
+
+		tokens, err := c.IAuth.VerifyMFA(r.Context(), &req, deviceInfo, ipAddress)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		utils.SetRefreshTokenCookie(w, tokens.RefreshToken, false)
+		writeJSON(w, http.StatusOK, tokens)
+	}
+}
+
+func (c *Controller) EnrollTOTP() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		enrollment, err := c.IAuth.EnrollTOTP(r.Context(), claims)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, enrollment)
+	}
+}
+
+func (c *Controller) ConfirmTOTP() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		var req dto.TOTPCodeRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		codes, err := c.IAuth.ConfirmTOTP(r.Context(), claims, req.Code)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
+
+func (c *Controller) DisableTOTP() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		var req dto.DisableTOTPRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if err := c.IAuth.DisableTOTP(r.Context(), claims, &req, utils.GetIPAddress(r)); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (c *Controller) RegenerateRecoveryCodes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		var req dto.TOTPCodeRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		codes, err := c.IAuth.RegenerateRecoveryCodes(r.Context(), claims, req.Code)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
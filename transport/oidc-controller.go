@@ -0,0 +1,78 @@
+package transport
+
+import (
+	errors2 "DBManager/internal/service/errors"
+	"DBManager/internal/shared/config"
+	"DBManager/internal/shared/utils"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+)
+
+// oidcStateCookie - куки, которой state входа через провайдера привязывается к браузеру, начавшему вход.
+// Без неё злоумышленник мог бы подсунуть жертве ссылку OIDCCallback со своим кодом и войти ею в свой аккаунт.
+const oidcStateCookie = "oidc_state"
+
+// OIDCLogin - перенаправляет пользователя на страницу входа внешнего провайдера.
+func (c *Controller) OIDCLogin() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		loginURL, state, err := c.IAuth.OIDCLoginURL(r.Context())
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		// SameSite=Lax: куки должна прийти с переходом от провайдера обратно к нам.
+		http.SetCookie(w, &http.Cookie{
+			Name:     oidcStateCookie,
+			Value:    state,
+			Path:     "/",
+			MaxAge:   int(config.OIDCConfig().StateTTL.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, loginURL, http.StatusFound)
+	}
+}
+
+// OIDCCallback - возврат пользователя от провайдера: обмен кода на пару токенов, как в SignIn.
+func (c *Controller) OIDCCallback() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		// Куки больше не нужна при любом исходе.
+		http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Value: "", Path: "/", MaxAge: -1, HttpOnly: true})
+
+		// Пользователь отказался от входа или провайдер его не пустил.
+		if providerErr := query.Get("error"); providerErr != "" {
+			slog.Info("Провайдер OIDC вернул ошибку", "error", providerErr, "description", query.Get("error_description"))
+			writeError(w, r, errors2.ErrOIDCLoginFailed)
+			return
+		}
+
+		state := query.Get("state")
+		cookie, err := r.Cookie(oidcStateCookie)
+		if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+			writeError(w, r, errors2.ErrOIDCStateInvalid)
+			return
+		}
+
+		deviceInfo := r.Header.Get("X-Device-Info")
+		ipAddress := utils.GetIPAddress(r)
+
+		tokens, challenge, err := c.IAuth.OIDCCallback(r.Context(), query.Get("code"), state, deviceInfo, ipAddress)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		// Подключена вторая ступень - токены выдаст VerifyMFA.
+		if challenge != nil {
+			writeJSON(w, http.StatusOK, challenge)
+			return
+		}
+
+		utils.SetRefreshTokenCookie(w, tokens.RefreshToken, false)
+		writeJSON(w, http.StatusOK, tokens)
+	}
+}
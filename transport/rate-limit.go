@@ -0,0 +1,49 @@
+package transport
+
+import (
+	"DBManager/internal/shared/dto/config"
+	"DBManager/internal/shared/utils"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimit - middleware, ограничивающий частоту запросов по политике policy.
+// Авторизованные запросы считаются по ID пользователя, остальные - по IP адресу.
+// При недоступности хранилища лимитов запрос пропускается: ограничение не должно останавливать API.
+func (c *Controller) RateLimit(policy config.RateLimitPolicy, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := "ip:" + utils.GetIPAddress(r)
+		if userID, ok := utils.UserIDFromContext(r.Context()); ok {
+			key = "user:" + strconv.Itoa(userID)
+		}
+
+		res, err := c.limiter.Allow(r.Context(), policy.Name+":"+key, policy)
+		if err != nil {
+			slog.Error("RateLimit error", "policy", policy.Name, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Period.Seconds())))
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+
+		if !res.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			writeError(w, r, errRateLimited)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// ceilSeconds - округляет длительность вверх до целых секунд, чтобы клиент не повторил запрос раньше времени.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,40 @@
+package transport
+
+import (
+	"DBManager/internal/shared/utils"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// requestIDHeader - заголовок с идентификатором запроса: принимается от прокси и возвращается клиенту.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength - идентификатор от клиента длиннее этого заменяется своим, чтобы не раздувать логи.
+const maxRequestIDLength = 128
+
+// RequestID - middleware, присваивающий запросу идентификатор. Он попадает в ответ, в тело ошибок и в логи,
+// так по ответу клиента можно найти запрос в логах. Идентификатор от прокси сохраняется, если он безопасен.
+func (c *Controller) RequestID(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		w.Header().Set(requestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(utils.ContextWithRequestID(r.Context(), requestID)))
+	}
+}
+
+// validRequestID - допускает только печатные символы без пробелов, чтобы идентификатор нельзя было использовать для подделки логов.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
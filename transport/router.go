@@ -2,34 +2,111 @@ package transport
 
 import (
 	"DBManager/internal/shared/config"
-	"log"
+	cfgdto "DBManager/internal/shared/dto/config"
+	"DBManager/internal/shared/rbac"
+	"DBManager/internal/shared/utils"
 	"log/slog"
 	"net/http"
 )
 
-// GoRouter - запускает сервер на указанном порту в .env файле.
-func GoRouter(c *Controller) {
+// NewServer - HTTP сервер со всеми маршрутами и таймаутами из конфигурации. Запуск и остановка - на вызывающем:
+// ListenAndServe и Shutdown, который дожидается завершения начатых запросов.
+func NewServer(c *Controller, cfg *cfgdto.HTTPConfig) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           Routes(c),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		// Ошибки сервера (обрыв TLS, паника в обработчике) пишем тем же логгером, что и остальное приложение.
+		ErrorLog: slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	}
+}
+
+// Routes - все маршруты приложения вместе с общими middleware.
+func Routes(c *Controller) http.Handler {
 	// Создаем главный роутер
 	mainRouter := http.NewServeMux()
 
 	// Роутер для авторизации (с middleware)
 	authRouter := http.NewServeMux()
-	authRouter.HandleFunc("/SignIn", c.SignIn())
-	authRouter.HandleFunc("/SignUp", c.SignUp())
+	// Публичные маршруты ограничиваются по IP: вход и обновление токенов - по политике auth,
+	// запросы, отправляющие письма, - по более строгой политике email.
+	limits := config.RateLimitConfig()
+	authRouter.HandleFunc("/SignIn", c.RateLimit(limits.Auth, c.SignIn()))
+	authRouter.HandleFunc("/SignUp", c.RateLimit(limits.Email, c.SignUp()))
+	// Обновление токенов аутентифицируется Refresh токеном из куки, Access Token к этому моменту может истечь.
+	authRouter.HandleFunc("/Refresh", c.RateLimit(limits.Auth, c.RefreshTokens()))
+	authRouter.HandleFunc("/VerifyEmail", c.RateLimit(limits.Auth, c.VerifyEmail()))
+	authRouter.HandleFunc("/ResendVerification", c.RateLimit(limits.Email, c.ResendVerification()))
+	authRouter.HandleFunc("/ForgotPassword", c.RateLimit(limits.Email, c.ForgotPassword()))
+	authRouter.HandleFunc("/ResetPassword", c.RateLimit(limits.Auth, c.ResetPassword()))
+	authRouter.HandleFunc("/VerifyMFA", c.RateLimit(limits.Auth, c.VerifyMFA()))
+	// Вход через внешнего провайдера OpenID Connect.
+	authRouter.HandleFunc("/OIDCLogin", c.RateLimit(limits.Auth, c.OIDCLogin()))
+	authRouter.HandleFunc("/OIDCCallback", c.RateLimit(limits.Auth, c.OIDCCallback()))
+	// Публичные ключи для проверки Access токенов другими сервисами.
+	authRouter.HandleFunc("/.well-known/jwks.json", c.JWKS())
 
-	// Роутер для общих запросов (с middleware авторизации)
+	// Роутер для общих запросов (с middleware авторизации).
+	// Действия с учётной записью и сессиями доступны только при входе по паролю, не по API ключу.
 	generalRouter := http.NewServeMux()
-	generalRouter.HandleFunc("/LogOut", c.LogOut())
-	generalRouter.HandleFunc("/Refresh", c.RefreshTokens())
+	generalRouter.HandleFunc("/LogOut", c.SessionOnly(c.LogOut()))
+	generalRouter.HandleFunc("/ChangePassword", c.SessionOnly(c.ChangePassword()))
 
-	// Подключаем роутеры с соответствующими middleware
-	mainRouter.Handle("/", authRouter)                       // Без middleware авторизации
-	mainRouter.Handle("/a/", c.Authorization(generalRouter)) // С middleware
+	// Двухфакторная аутентификация.
+	generalRouter.HandleFunc("/EnrollTOTP", c.SessionOnly(c.EnrollTOTP()))
+	generalRouter.HandleFunc("/ConfirmTOTP", c.SessionOnly(c.ConfirmTOTP()))
+	generalRouter.HandleFunc("/DisableTOTP", c.SessionOnly(c.DisableTOTP()))
+	generalRouter.HandleFunc("/RegenerateRecoveryCodes", c.SessionOnly(c.RegenerateRecoveryCodes()))
+
+	// Сессии пользователя на разных устройствах.
+	generalRouter.HandleFunc("/Sessions", c.SessionOnly(c.ListSessions()))
+	generalRouter.HandleFunc("/RevokeSession", c.SessionOnly(c.RevokeSession()))
+	generalRouter.HandleFunc("/RevokeOtherSessions", c.SessionOnly(c.RevokeOtherSessions()))
+
+	// API ключи для интеграций.
+	generalRouter.HandleFunc("/APIKeys", c.SessionOnly(c.ListAPIKeys()))
+	generalRouter.HandleFunc("/CreateAPIKey", c.SessionOnly(c.CreateAPIKey()))
+	generalRouter.HandleFunc("/RevokeAPIKey", c.SessionOnly(c.RevokeAPIKey()))
 
-	addr := config.HTTPConfig().Addr
+	// Управление пользователями.
+	generalRouter.HandleFunc("/ChangeUserRole", c.Require(rbac.PermUsersManage, c.ChangeUserRole()))
+	generalRouter.HandleFunc("/UnlockUser", c.Require(rbac.PermUsersManage, c.UnlockUser()))
 
-	slog.Info("Сервер успешно запущен, на порту ", addr)
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatalf("Не удалось запустить сервер на порту %s. Ошибка: %s", addr, err)
+	// Каталог товаров.
+	generalRouter.HandleFunc("/CreateItem", c.Require(rbac.PermItemsWrite, c.CreateItem()))
+	generalRouter.HandleFunc("/GetItem", c.Require(rbac.PermItemsRead, c.GetItem()))
+	generalRouter.HandleFunc("/Items", c.Require(rbac.PermItemsRead, c.ListItems()))
+	generalRouter.HandleFunc("/UpdateItem", c.Require(rbac.PermItemsWrite, c.UpdateItem()))
+	generalRouter.HandleFunc("/DeleteItem", c.Require(rbac.PermItemsWrite, c.DeleteItem()))
+
+	// Склады, места хранения и остатки.
+	generalRouter.HandleFunc("/CreateWarehouse", c.Require(rbac.PermWarehousesWrite, c.CreateWarehouse()))
+	generalRouter.HandleFunc("/Warehouses", c.Require(rbac.PermWarehousesRead, c.ListWarehouses()))
+	generalRouter.HandleFunc("/CreateLocation", c.Require(rbac.PermWarehousesWrite, c.CreateLocation()))
+	generalRouter.HandleFunc("/Locations", c.Require(rbac.PermWarehousesRead, c.ListLocations()))
+	generalRouter.HandleFunc("/ItemStock", c.Require(rbac.PermStockRead, c.GetItemStock()))
+
+	// Журнал движений товара.
+	generalRouter.HandleFunc("/RecordMovement", c.Require(rbac.PermMovementsWrite, c.RecordMovement()))
+	generalRouter.HandleFunc("/Movements", c.Require(rbac.PermMovementsRead, c.ListMovements()))
+	// Сверка только читает, исправление меняет остатки и требует права на корректировку.
+	generalRouter.HandleFunc("GET /ReconcileStock", c.Require(rbac.PermStockAudit, c.ReconcileStock()))
+	generalRouter.HandleFunc("POST /ApplyStockReconciliation", c.Require(rbac.PermStockAdjust, c.ApplyStockReconciliation()))
+
+	// Подключаем роутеры с соответствующими middleware
+	mainRouter.Handle("/", authRouter)                                                                        // Без middleware авторизации
+	mainRouter.Handle("/a/", c.Authorization(c.RateLimit(limits.API, http.StripPrefix("/a", generalRouter)))) // С middleware
+
+	// Список уже проверен при загрузке конфигурации.
+	trustedProxies, err := utils.ParseTrustedProxies(config.HTTPConfig().TrustedProxies)
+	if err != nil {
+		panic(err)
 	}
+
+	// Идентификатор запроса и язык ответа определяются раньше всех middleware, чтобы попасть в любой ответ с ошибкой.
+	// IP клиента - тоже: по нему считаются лимиты.
+	return c.RequestID(c.Locale(c.ClientIP(trustedProxies, mainRouter)))
 }
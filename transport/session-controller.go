@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/utils"
+	"net/http"
+)
+
+func (c *Controller) ListSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		sessions, err := c.IAuth.ListSessions(r.Context(), claims)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, sessions)
+	}
+}
+
+func (c *Controller) RevokeSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		var req dto.RevokeSessionRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if err := c.IAuth.RevokeSession(r.Context(), claims, req.SessionID); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (c *Controller) RevokeOtherSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		count, err := c.IAuth.RevokeOtherSessions(r.Context(), claims)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]int{"revoked": count})
+	}
+}
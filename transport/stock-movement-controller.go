@@ -0,0 +1,99 @@
+package transport
+
+import (
+	"DBManager/internal/shared/dto"
+	"DBManager/internal/shared/utils"
+	"net/http"
+	"strconv"
+)
+
+func (c *Controller) RecordMovement() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Движение всегда привязывается к пользователю из Access Token.
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		var req dto.MovementRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		movement, err := c.IManager.RecordMovement(r.Context(), claims, &req)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, movement)
+	}
+}
+
+func (c *Controller) ListMovements() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		// Все фильтры необязательны, некорректные значения считаем нулём.
+		itemID, _ := strconv.Atoi(query.Get("item_id"))
+		locationID, _ := strconv.Atoi(query.Get("location_id"))
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		offset, _ := strconv.Atoi(query.Get("offset"))
+
+		movements, err := c.IManager.ListMovements(r.Context(), &dto.MovementFilter{
+			ItemID:     itemID,
+			LocationID: locationID,
+			Type:       query.Get("type"),
+			Limit:      limit,
+			Offset:     offset,
+		})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, movements)
+	}
+}
+
+// ReconcileStock - сверка остатков с журналом движений, только чтение.
+func (c *Controller) ReconcileStock() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemID, _ := strconv.Atoi(r.URL.Query().Get("item_id"))
+
+		discrepancies, err := c.IManager.ReconcileStock(r.Context(), itemID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, discrepancies)
+	}
+}
+
+// ApplyStockReconciliation - исправление остатков по журналу движений от имени пользователя из Access Token.
+func (c *Controller) ApplyStockReconciliation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			writeError(w, r, errUnauthorized)
+			return
+		}
+
+		var req dto.StockReconciliationRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		fixed, err := c.IManager.ApplyStockReconciliation(r.Context(), claims.UserID, req.ItemID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, fixed)
+	}
+}
@@ -0,0 +1,104 @@
+package transport
+
+import (
+	"DBManager/internal/shared/dto"
+	"net/http"
+	"strconv"
+)
+
+func (c *Controller) CreateWarehouse() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.CreateWarehouseRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		warehouse, err := c.IManager.CreateWarehouse(r.Context(), &req)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, warehouse)
+	}
+}
+
+func (c *Controller) ListWarehouses() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		warehouses, err := c.IManager.ListWarehouses(r.Context())
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, warehouses)
+	}
+}
+
+func (c *Controller) CreateLocation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.CreateLocationRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		location, err := c.IManager.CreateLocation(r.Context(), &req)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, location)
+	}
+}
+
+func (c *Controller) ListLocations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		warehouseID, err := strconv.Atoi(r.URL.Query().Get("warehouse_id"))
+		if err != nil {
+			writeError(w, r, invalidParam("warehouse_id"))
+			return
+		}
+
+		locations, err := c.IManager.ListLocations(r.Context(), warehouseID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, locations)
+	}
+}
+
+// GetItemStock - остаток товара по всем складам, либо по одному, если передан warehouse_id.
+func (c *Controller) GetItemStock() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		itemID, err := strconv.Atoi(query.Get("item_id"))
+		if err != nil {
+			writeError(w, r, invalidParam("item_id"))
+			return
+		}
+
+		var stock *dto.ItemStock
+		if query.Has("warehouse_id") {
+			warehouseID, convErr := strconv.Atoi(query.Get("warehouse_id"))
+			if convErr != nil {
+				writeError(w, r, invalidParam("warehouse_id"))
+				return
+			}
+			stock, err = c.IManager.GetItemStockInWarehouse(r.Context(), itemID, warehouseID)
+		} else {
+			stock, err = c.IManager.GetItemStock(r.Context(), itemID)
+		}
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, stock)
+	}
+}